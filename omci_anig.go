@@ -16,6 +16,11 @@
 
 package core
 
+import (
+	"errors"
+	"fmt"
+)
+
 type AniGAttributes int
 const (
 	_								= iota
@@ -37,8 +42,122 @@ const (
 	UpperTransmitPowerThreshold	AniGAttributes	= 0x0001
 )
 
+// ANI-G attribute numbers
+const (
+	AniGSrIndication = iota + 1
+	AniGTotalTcontNumber
+	AniGGemBlockLength
+	AniGPiggybackDbaReporting
+	AniGWholeOntDbaReporting
+	AniGSfThreshold
+	AniGSdThreshold
+	AniGArc
+	AniGArcInterval
+	AniGOpticalSignalLevel
+	AniGLowerOpticalThreshold
+	AniGUpperOpticalThreshold
+	AniGOntResponseTime
+	AniGTransmitOpticalLevel
+	AniGLowerTransmitPowerThreshold
+	AniGUpperTransmitPowerThreshold
+)
+
+// AniGLowRxOpticalPowerAlarm is the ANI-G alarm number of the low received optical power alarm,
+// G.988 has no ANI-G loss of signal alarm so a LOS is reported with it
+const AniGLowRxOpticalPowerAlarm uint = 0
+
+// aniGLosOpticalSignalLevel is the optical signal level reported by the ANI-G during a loss of signal,
+// the lowest level the attribute can encode
+var aniGLosOpticalSignalLevel = []byte{0x80, 0x00}
+
+// ANI-G is created by the ONU, its values match the ones reported in the MIB upload
+func init() {
+	MeDefinitions[ANIG] = &MeDefinition{
+		Name: "AniG",
+		Attributes: []AttributeDefinition{
+			// Status reporting is supported
+			{Name: "SrIndication", Size: 1, Access: AttrRead, Default: []byte{0x01}},
+			{Name: "TotalTcontNumber", Size: 2, Access: AttrRead, Default: []byte{0x00, NumTcont}},
+			{Name: "GemBlockLength", Size: 2, Access: AttrRead | AttrWrite, Default: []byte{0x00, 0x30},
+				Range: true, Min: 1},
+			{Name: "PiggybackDbaReporting", Size: 1, Access: AttrRead},
+			// Deprecated, still served as zero since OLTs read the whole ANI-G
+			{Name: "WholeOntDbaReporting", Size: 1, Access: AttrRead},
+			// Bit error rate thresholds of 10^-x
+			{Name: "SfThreshold", Size: 1, Access: AttrRead | AttrWrite, Default: []byte{0x05},
+				Range: true, Min: 3, Max: 8},
+			{Name: "SdThreshold", Size: 1, Access: AttrRead | AttrWrite, Default: []byte{0x09},
+				Range: true, Min: 4, Max: 10},
+			{Name: "Arc", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "ArcInterval", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "OpticalSignalLevel", Size: 2, Access: AttrRead, Default: EncodeFixedPoint(-16.216, OpticalLevelStep, 2),
+				Value: aniGOpticalSignalLevel},
+			{Name: "LowerOpticalThreshold", Size: 1, Access: AttrRead | AttrWrite, Default: []byte{0xff}},
+			{Name: "UpperOpticalThreshold", Size: 1, Access: AttrRead | AttrWrite, Default: []byte{0xff}},
+			{Name: "OntResponseTime", Size: 2, Access: AttrRead},
+			{Name: "TransmitOpticalLevel", Size: 2, Access: AttrRead, Default: EncodeFixedPoint(6.342, OpticalLevelStep, 2)},
+			// -63.5 dBm selects the ONU internal policy
+			{Name: "LowerTransmitPowerThreshold", Size: 1, Access: AttrRead | AttrWrite,
+				Default: EncodeFixedPoint(-63.5, PowerThresholdStep, 1)},
+			{Name: "UpperTransmitPowerThreshold", Size: 1, Access: AttrRead | AttrWrite,
+				Default: EncodeFixedPoint(-63.5, PowerThresholdStep, 1)},
+		},
+		Instances: func() []uint16 {
+			return []uint16{0x8001}
+		},
+	}
+}
+
+// SimulateOpticalLevels sets the received and transmitted optical power (in dBm) reported by the ANI-G of an ONU
+func SimulateOpticalLevels(oltId int, intfId uint32, onuId uint32, rxPower float64, txPower float64) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+		return errors.New(errmsg)
+	}
+	attrs, ok := state.getMe(ANIG, 0x8001)
+	if !ok {
+		return errors.New("ANI-G not found")
+	}
+
+	attrs[AniGOpticalSignalLevel] = EncodeFixedPoint(rxPower, OpticalLevelStep, 2)
+	attrs[AniGTransmitOpticalLevel] = EncodeFixedPoint(txPower, OpticalLevelStep, 2)
+	return nil
+}
+
+func aniGOpticalSignalLevel(s *OnuOmciState, instance uint16) []byte {
+	if s.aniLos {
+		return append([]byte{}, aniGLosOpticalSignalLevel...)
+	}
+	attrs, _ := s.getMe(ANIG, instance)
+	return attrs[AniGOpticalSignalLevel]
+}
+
+// SimulateAniLos sets or clears a loss of signal on the ANI-G of an ONU: while it lasts the ANI-G reports the
+// lowest optical signal level it can encode, the PON circuit pack is disabled and the low received optical
+// power alarm is raised
+func SimulateAniLos(oltId int, intfId uint32, onuId uint32, los bool) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer unlockAndNotify()
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+		return errors.New(errmsg)
+	}
+
+	state.aniLos = los
+	state.setAlarm(key, ANIG, 0x8001, AniGLowRxOpticalPowerAlarm, los)
+	return nil
+}
+
+// Deprecated: the ANI-G is served from MeDefinitions, see ANIGAttributeHandlers
 type ANIGAttributeHandler func(*uint, []byte) ([]byte, error)
 
+// Deprecated: the ANI-G is served from MeDefinitions, the handlers write the default values of its attributes
 var ANIGAttributeHandlers = map[AniGAttributes]ANIGAttributeHandler{
 	SRIndication: GetSRIndication,
 	OpticalSignalLevel: GetOpticalSignalLevel,
@@ -58,7 +177,8 @@ var ANIGAttributeHandlers = map[AniGAttributes]ANIGAttributeHandler{
 	UpperTransmitPowerThreshold: GetUpperTransmitPowerThreshold,
 }
 
-
+// Deprecated: the ANI-G is served from MeDefinitions, GetANIGAttributes writes the default values of the
+// attributes of content
 func GetANIGAttributes(pos *uint, pkt []byte, content OmciContent) ([]byte, error) {
 	AttributesMask := getAttributeMask(content)
 
@@ -79,106 +199,82 @@ func GetANIGAttributes(pos *uint, pkt []byte, content OmciContent) ([]byte, erro
 
 }
 
-
+// Deprecated: use ANIGAttributeHandlers
 func GetSRIndication(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x01
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGSrIndication, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetOpticalSignalLevel(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0xd7
-	*pos++
-	pkt[*pos] = 0xa9
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGOpticalSignalLevel, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetTotalTcontNumber(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x08
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGTotalTcontNumber, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetGEMBlockLength(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x00
-	*pos++
-	pkt[*pos] = 0x30
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGGemBlockLength, pos, pkt)
 }
 
-func GetPiggybackDBAReporting (pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x00
-	*pos++
-	return pkt, nil
+// Deprecated: use ANIGAttributeHandlers
+func GetPiggybackDBAReporting(pos *uint, pkt []byte) ([]byte, error) {
+	return writeDefaultAttribute(ANIG, AniGPiggybackDbaReporting, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetWholeONTDBAReporting(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x00
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGWholeOntDbaReporting, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetUpperOpticalThreshold(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0xff
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGUpperOpticalThreshold, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetSFThreshold(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x03
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGSfThreshold, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetSDThreshold(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x05
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGSdThreshold, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetARC(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x00
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGArc, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetARCInterval(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x00
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGArcInterval, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetONTResponseTime(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x00
-	*pos++
-	pkt[*pos] = 0x00
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGOntResponseTime, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetLowerOpticalThreshold(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0xff
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGLowerOpticalThreshold, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetTransmitOpticalLeval(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x07
-	*pos++
-	pkt[*pos] = 0x1e
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGTransmitOpticalLevel, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetLowerTransmitPowerThreshold(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x81
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGLowerTransmitPowerThreshold, pos, pkt)
 }
 
+// Deprecated: use ANIGAttributeHandlers
 func GetUpperTransmitPowerThreshold(pos *uint, pkt []byte) ([]byte, error) {
-	pkt[*pos] = 0x81
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(ANIG, AniGUpperTransmitPowerThreshold, pos, pkt)
 }
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestAniGCapabilities(t *testing.T) {
+	onu := newTestOnu(t)
+	values := onu.mustGet(ANIG, 0x8001, uint16(SRIndication|TotalTcontNumber))
+	if values[0] != 0x01 {
+		t.Errorf("SR indication is %d, expected 1", values[0])
+	}
+	if total := binary.BigEndian.Uint16(values[1:3]); total != NumTcont {
+		t.Errorf("Total T-CONT number is %d, expected %d", total, NumTcont)
+	}
+}
+
+func TestGetANIGAttributes(t *testing.T) {
+	pkt := make([]byte, BaselineFrameLength)
+	pos := uint(getAttributesStart)
+	pkt, _ = GetANIGAttributes(&pos, pkt, OmciContent{0x20, 0x40})
+
+	// The attributes follow in mask order, after the mask
+	if mask := binary.BigEndian.Uint16(pkt[9:11]); mask != uint16(GEMBlockLength|OpticalSignalLevel) {
+		t.Errorf("Attribute mask is %#04x", mask)
+	}
+	if length := binary.BigEndian.Uint16(pkt[11:13]); length != 0x30 {
+		t.Errorf("GEM block length is %#04x, expected 0x30", length)
+	}
+	level := MeDefinitions[ANIG].Attributes[AniGOpticalSignalLevel-1].Default
+	if !bytes.Equal(pkt[13:15], level) {
+		t.Errorf("Optical signal level is %x, expected %x", pkt[13:15], level)
+	}
+	if pos != 15 {
+		t.Errorf("Position is %d, expected 15", pos)
+	}
+}
+
+func TestAniGOpticalAttributes(t *testing.T) {
+	onu := newTestOnu(t)
+	if err := SimulateOpticalLevels(0, onu.intfId, onu.onuId, -20.5, 2.25); err != nil {
+		t.Fatal(err)
+	}
+
+	// All the ANI-G attributes fit in a baseline Get response
+	resp := onu.send(Get, ANIG, 0x8001, []byte{0xff, 0xff})
+	if result := onu.result(resp); result != Success {
+		t.Fatalf("Get got result %d", result)
+	}
+	if served := binary.BigEndian.Uint16(resp[9:11]); served != 0xffff {
+		t.Errorf("Served mask is %#04x, expected 0xffff", served)
+	}
+	if failed := binary.BigEndian.Uint16(resp[getAttributesEnd+2 : getAttributesEnd+4]); failed != 0 {
+		t.Errorf("Failed mask is %#04x, expected 0", failed)
+	}
+
+	r := NewContentReader(resp[getAttributesStart:getAttributesEnd])
+	read := func(n int) []byte {
+		b, err := r.ReadBytes(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	// SR indication to ARC interval
+	for i, expected := range [][]byte{{0x01}, {0x00, NumTcont}, {0x00, 0x30}, {0x00}, {0x00}, {0x05}, {0x09}, {0x00}, {0x00}} {
+		if value := read(len(expected)); string(value) != string(expected) {
+			t.Errorf("Attribute %d is %x, expected %x", i+1, value, expected)
+		}
+	}
+	if level := DecodeFixedPoint(read(2), OpticalLevelStep); level != -20.5 {
+		t.Errorf("Optical signal level is %.3f dBm, expected -20.5", level)
+	}
+	if thresholds := read(2); thresholds[0] != 0xff || thresholds[1] != 0xff {
+		t.Errorf("Optical thresholds are %x, expected ffff", thresholds)
+	}
+	read(2) // ONT response time
+	if level := DecodeFixedPoint(read(2), OpticalLevelStep); level != 2.25 {
+		t.Errorf("Transmit optical level is %.3f dBm, expected 2.25", level)
+	}
+	for _, name := range []string{"Lower", "Upper"} {
+		if threshold := DecodeFixedPoint(read(1), PowerThresholdStep); threshold != -63.5 {
+			t.Errorf("%s transmit power threshold is %.1f dBm, expected -63.5", name, threshold)
+		}
+	}
+}
+
+func TestGetSpillsToFailedMask(t *testing.T) {
+	onu := newTestOnu(t)
+
+	// The type, number of ports, serial number and version fill 24 bytes, the vendor id doesn't fit
+	resp := onu.send(Get, CircuitPack, EthernetCircuitPack, []byte{0xf8, 0x00})
+	if result := onu.result(resp); result != AttributeFailure {
+		t.Errorf("Get got result %d, expected %d", result, AttributeFailure)
+	}
+	if served := binary.BigEndian.Uint16(resp[9:11]); served != 0xf000 {
+		t.Errorf("Served mask is %#04x, expected 0xf000", served)
+	}
+	if failed := binary.BigEndian.Uint16(resp[getAttributesEnd+2 : getAttributesEnd+4]); failed != 0x0800 {
+		t.Errorf("Failed mask is %#04x, expected 0x0800", failed)
+	}
+}
+
+func TestAniGDeprecatedAttribute(t *testing.T) {
+	onu := newTestOnu(t)
+
+	// The first 5 attributes, the last one being the deprecated whole ONT DBA reporting
+	mask := uint16(0xf800)
+	resp := onu.send(Get, ANIG, 0x8001, []byte{byte(mask >> 8), byte(mask)})
+	if result := onu.result(resp); result != Success {
+		t.Fatalf("Get got result %d, expected %d", result, Success)
+	}
+	if served := binary.BigEndian.Uint16(resp[9:11]); served != mask {
+		t.Errorf("Get served mask %#04x, expected %#04x", served, mask)
+	}
+	if resp[getAttributesStart+6] != 0x00 {
+		t.Errorf("Whole ONT DBA reporting is %d, expected 0", resp[getAttributesStart+6])
+	}
+	if failed := binary.BigEndian.Uint16(resp[getAttributesEnd+2:]); failed != 0 {
+		t.Errorf("Failed mask is %#04x, expected none", failed)
+	}
+}
+
+func TestSimulateAniLos(t *testing.T) {
+	onu := newTestOnu(t)
+	level := attributeMaskBit(AniGOpticalSignalLevel)
+	operationalState := attributeMaskBit(CircuitPackOperationalState)
+	normal := onu.mustGet(ANIG, 0x8001, level)[:2]
+
+	if err := SimulateAniLos(0, onu.intfId, onu.onuId, true); err != nil {
+		t.Fatal(err)
+	}
+	if msg := onu.notification(); msg.Type != AlarmRaised || !alarmRaised(msg.Packet, AniGLowRxOpticalPowerAlarm) {
+		t.Errorf("Got %s %x, expected the low received optical power alarm raised", msg.Type, msg.Packet)
+	}
+	if value := onu.mustGet(ANIG, 0x8001, level)[:2]; !bytes.Equal(value, aniGLosOpticalSignalLevel) {
+		t.Errorf("Optical signal level is %x during a LOS, expected %x", value, aniGLosOpticalSignalLevel)
+	}
+	if state := onu.mustGet(CircuitPack, PonCircuitPack, operationalState)[0]; state != 0x01 {
+		t.Errorf("PON circuit pack operational state is %d during a LOS, expected disabled", state)
+	}
+
+	if err := SimulateAniLos(0, onu.intfId, onu.onuId, false); err != nil {
+		t.Fatal(err)
+	}
+	if msg := onu.notification(); msg.Type != AlarmCleared || alarmRaised(msg.Packet, AniGLowRxOpticalPowerAlarm) {
+		t.Errorf("Got %s %x, expected the low received optical power alarm cleared", msg.Type, msg.Packet)
+	}
+	if value := onu.mustGet(ANIG, 0x8001, level)[:2]; !bytes.Equal(value, normal) {
+		t.Errorf("Optical signal level is %x after the LOS, expected %x", value, normal)
+	}
+	if state := onu.mustGet(CircuitPack, PonCircuitPack, operationalState)[0]; state != 0x00 {
+		t.Errorf("PON circuit pack operational state is %d after the LOS, expected enabled", state)
+	}
+}
+
+func TestAniGGemBlockLengthRange(t *testing.T) {
+	onu := newTestOnu(t)
+	mask := attributeMaskBit(AniGGemBlockLength)
+
+	result, failed := onu.set(ANIG, 0x8001, mask, 0x00, 0x00)
+	if result != AttributeFailure || failed != mask {
+		t.Errorf("Set of a zero GEM block length got result %d failed mask %#04x, expected %d and %#04x",
+			result, failed, AttributeFailure, mask)
+	}
+	if value := onu.mustGet(ANIG, 0x8001, mask)[:2]; !bytes.Equal(value, []byte{0x00, 0x30}) {
+		t.Errorf("GEM block length is %x after the failed Set, expected 0030", value)
+	}
+
+	if result, _ := onu.set(ANIG, 0x8001, mask, 0x00, 0x40); result != Success {
+		t.Fatalf("Set of a GEM block length of 64 got result %d", result)
+	}
+	if value := onu.mustGet(ANIG, 0x8001, mask)[:2]; !bytes.Equal(value, []byte{0x00, 0x40}) {
+		t.Errorf("GEM block length is %x, expected 0040", value)
+	}
+	// The block length is only bounded by its size above
+	if result, _ := onu.set(ANIG, 0x8001, mask, 0xFF, 0xFF); result != Success {
+		t.Errorf("Set of the largest GEM block length got result %d", result)
+	}
+}
+
+func TestAniGPartialSet(t *testing.T) {
+	onu := newTestOnu(t)
+	tconts := attributeMaskBit(AniGTotalTcontNumber)
+	blockLength := attributeMaskBit(AniGGemBlockLength)
+
+	// The read-only number of T-CONTs fails, the GEM block length is still written
+	result, failed := onu.set(ANIG, 0x8001, tconts|blockLength, 0x00, 0x10, 0x00, 0x40)
+	if result != AttributeFailure || failed != tconts {
+		t.Errorf("Set got result %d failed mask %#04x, expected %d and %#04x", result, failed, AttributeFailure, tconts)
+	}
+	values := onu.mustGet(ANIG, 0x8001, tconts|blockLength)
+	if !bytes.Equal(values[:4], []byte{0x00, NumTcont, 0x00, 0x40}) {
+		t.Errorf("T-CONTs and GEM block length are %x, expected 00%02x0040", values[:4], NumTcont)
+	}
+}
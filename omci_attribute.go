@@ -0,0 +1,137 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// Attribute attribute numbers
+const (
+	AttributeMeName = iota + 1
+	AttributeMeSize
+	AttributeMeAccess
+	AttributeMeFormat
+	AttributeMeLowerLimit
+	AttributeMeUpperLimit
+	AttributeMeBitField
+	AttributeMeCodePointsTable
+	AttributeMeSupport
+)
+
+// Attribute formats
+const (
+	attributeFormatString          uint8 = 5
+	attributeFormatUnsignedInteger uint8 = 4
+	attributeFormatTable           uint8 = 7
+)
+
+// The Attribute MEs describe the attributes of the ME classes in MeDefinitions, listed in the
+// attributes table of their Managed Entity ME, see attributeMeInstance
+func init() {
+	MeDefinitions[AttributeME] = &MeDefinition{
+		Name: "Attribute",
+		Attributes: []AttributeDefinition{
+			{Name: "Name", Size: 25, Access: AttrRead, Value: attributeName},
+			{Name: "Size", Size: 2, Access: AttrRead, Value: attributeSize},
+			{Name: "Access", Size: 1, Access: AttrRead, Value: attributeAccess},
+			{Name: "Format", Size: 1, Access: AttrRead, Value: attributeFormat},
+			{Name: "LowerLimit", Size: 4, Access: AttrRead, Value: attributeLowerLimit},
+			{Name: "UpperLimit", Size: 4, Access: AttrRead, Value: attributeUpperLimit},
+			{Name: "BitField", Size: 4, Access: AttrRead},
+			{Name: "CodePointsTable", Size: 2, Access: AttrRead, Table: true},
+			// Supported
+			{Name: "Support", Size: 1, Access: AttrRead, Default: []byte{0x01}},
+		},
+		Instances: attributeMeInstances,
+		Computed:  true,
+	}
+}
+
+func attributeMeInstances() []uint16 {
+	var instances []uint16
+	for class, def := range MeDefinitions {
+		for i := range def.Attributes {
+			if instance, ok := attributeMeInstance(class, i+1); ok {
+				instances = append(instances, instance)
+			}
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i] < instances[j] })
+	return instances
+}
+
+// attributeDefinitionOf returns the definition of the attribute described by an Attribute ME instance
+func attributeDefinitionOf(instance uint16) AttributeDefinition {
+	return MeDefinitions[OmciClass(instance>>4)].Attributes[instance&0x0F]
+}
+
+func attributeName(state *OnuOmciState, instance uint16) []byte {
+	name := make([]byte, 25)
+	copy(name, attributeDefinitionOf(instance).Name)
+	return name
+}
+
+func attributeSize(state *OnuOmciState, instance uint16) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, uint16(attributeDefinitionOf(instance).Size))
+	return b
+}
+
+// attributeAccess returns the access code of an attribute: 1 read, 2 write and 3 read/write,
+// plus 3 if it is set-by-create
+func attributeAccess(state *OnuOmciState, instance uint16) []byte {
+	access := attributeDefinitionOf(instance).Access
+	code := uint8(access & (AttrRead | AttrWrite))
+	if access&AttrSetByCreate != 0 {
+		code += 3
+	}
+	return []byte{code}
+}
+
+// attributeFormat tells the tables apart, the attributes longer than an integer are reported as strings
+func attributeFormat(state *OnuOmciState, instance uint16) []byte {
+	attrDef := attributeDefinitionOf(instance)
+	switch {
+	case attrDef.Table:
+		return []byte{attributeFormatTable}
+	case attrDef.Size > 4:
+		return []byte{attributeFormatString}
+	default:
+		return []byte{attributeFormatUnsignedInteger}
+	}
+}
+
+// attributeLowerLimit and attributeUpperLimit report the Range of an attribute, 0 without one
+func attributeLowerLimit(state *OnuOmciState, instance uint16) []byte {
+	b := make([]byte, 4)
+	if attrDef := attributeDefinitionOf(instance); attrDef.Range {
+		min, _ := attrDef.bounds()
+		binary.BigEndian.PutUint32(b, uint32(min))
+	}
+	return b
+}
+
+func attributeUpperLimit(state *OnuOmciState, instance uint16) []byte {
+	b := make([]byte, 4)
+	if attrDef := attributeDefinitionOf(instance); attrDef.Range {
+		_, max := attrDef.bounds()
+		binary.BigEndian.PutUint32(b, uint32(max))
+	}
+	return b
+}
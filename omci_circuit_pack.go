@@ -0,0 +1,220 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Circuit Pack attribute numbers
+const (
+	CircuitPackType = iota + 1
+	CircuitPackNumberOfPorts
+	CircuitPackSerialNumber
+	CircuitPackVersion
+	CircuitPackVendorId
+	CircuitPackAdministrativeState
+	CircuitPackOperationalState
+	CircuitPackBridgedOrIpInd
+	CircuitPackEquipmentId
+	CircuitPackCardConfiguration
+	CircuitPackTotalTcontBufferNumber
+	CircuitPackTotalPriorityQueueNumber
+	CircuitPackTotalTrafficSchedulerNumber
+	CircuitPackPowerShedOverride
+)
+
+// Circuit pack instances, slot 1 holds the Ethernet UNIs and slot 128 the PON interface
+const (
+	EthernetCircuitPack uint16 = 0x0101
+	PonCircuitPack      uint16 = 0x0180
+)
+
+// Granularity of the circuit pack telemetry, as reported in the ANI-G self test results
+const (
+	TemperatureStep   = 1.0 / 256 // degrees Celsius
+	SupplyVoltageStep = 0.02      // V
+	LaserBiasStep     = 0.002     // mA
+)
+
+// CircuitPackTelemetry is the telemetry of a circuit pack, the one of the PON circuit pack is reported
+// by the self test of the ANI-G
+type CircuitPackTelemetry struct {
+	Temperature   float64 // degrees Celsius
+	SupplyVoltage float64 // V
+	LaserBias     float64 // mA
+}
+
+// defaultCircuitPackTelemetry is the telemetry of the circuit packs until set otherwise
+var defaultCircuitPackTelemetry = CircuitPackTelemetry{Temperature: 40, SupplyVoltage: 3.3, LaserBias: 20}
+
+// CircuitPackTemperatureYellowAlarm is the Circuit Pack alarm number of the temperature yellow alarm
+const CircuitPackTemperatureYellowAlarm uint = 4
+
+// HighTemperatureThreshold is the temperature (in degrees Celsius) above which the temperature yellow alarm is raised
+const HighTemperatureThreshold = 85.0
+
+// Circuit Packs are created by the ONU, their values match the ones reported in the MIB upload
+func init() {
+	MeDefinitions[CircuitPack] = &MeDefinition{
+		Name: "CircuitPack",
+		Attributes: []AttributeDefinition{
+			{Name: "Type", Size: 1, Access: AttrRead},
+			{Name: "NumberOfPorts", Size: 1, Access: AttrRead},
+			{Name: "SerialNumber", Size: 8, Access: AttrRead,
+				Default: []byte{0x49, 0x53, 0x4b, 0x54, 0x71, 0xe8, 0x00, 0x80}},
+			{Name: "Version", Size: 14, Access: AttrRead,
+				Default: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x0c}},
+			{Name: "VendorId", Size: 4, Access: AttrRead, Default: []byte("BRCM")},
+			{Name: "AdministrativeState", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "OperationalState", Size: 1, Access: AttrRead, Value: circuitPackOperationalState},
+			{Name: "BridgedOrIpInd", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "EquipmentId", Size: 20, Access: AttrRead, Default: []byte("                    ")},
+			{Name: "CardConfiguration", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "TotalTcontBufferNumber", Size: 1, Access: AttrRead},
+			{Name: "TotalPriorityQueueNumber", Size: 1, Access: AttrRead},
+			{Name: "TotalTrafficSchedulerNumber", Size: 1, Access: AttrRead},
+			{Name: "PowerShedOverride", Size: 4, Access: AttrRead | AttrWrite},
+		},
+		Instances: func() []uint16 {
+			return []uint16{EthernetCircuitPack, PonCircuitPack}
+		},
+		Init: initCircuitPack,
+	}
+}
+
+func initCircuitPack(_ OnuKey, instance uint16, attrs MeAttributes) {
+	switch instance {
+	case EthernetCircuitPack:
+		// 10/100/1000 Ethernet
+		attrs[CircuitPackType][0] = 0x2f
+		attrs[CircuitPackNumberOfPorts][0] = 4
+		attrs[CircuitPackTotalPriorityQueueNumber][0] = 8
+	case PonCircuitPack:
+		// GPON 2488/1244
+		attrs[CircuitPackType][0] = 0xee
+		attrs[CircuitPackNumberOfPorts][0] = 1
+		attrs[CircuitPackTotalTcontBufferNumber][0] = 8
+		attrs[CircuitPackTotalPriorityQueueNumber][0] = 64
+		attrs[CircuitPackTotalTrafficSchedulerNumber][0] = 16
+	}
+}
+
+// circuitPackOperationalState reports the PON circuit pack disabled during a loss of signal
+func circuitPackOperationalState(s *OnuOmciState, instance uint16) []byte {
+	if instance == PonCircuitPack && s.aniLos {
+		return []byte{0x01}
+	}
+	attrs, _ := s.getMe(CircuitPack, instance)
+	return attrs[CircuitPackOperationalState]
+}
+
+// circuitPackTelemetry returns the telemetry of a circuit pack
+func (s *OnuOmciState) circuitPackTelemetry(instance uint16) CircuitPackTelemetry {
+	if telemetry, ok := s.telemetry[instance]; ok {
+		return telemetry
+	}
+	return defaultCircuitPackTelemetry
+}
+
+// GetCircuitPackTelemetry returns the telemetry of a circuit pack of an ONU
+func GetCircuitPackTelemetry(oltId int, intfId uint32, onuId uint32, instance uint16) (CircuitPackTelemetry, error) {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.RLock()
+	defer OnuOmciStateMapLock.RUnlock()
+	state, err := getCircuitPack(key, instance)
+	if err != nil {
+		return CircuitPackTelemetry{}, err
+	}
+	return state.circuitPackTelemetry(instance), nil
+}
+
+// SetTemperature sets the temperature (in degrees Celsius) of a circuit pack of an ONU, raising the
+// temperature yellow alarm when it exceeds HighTemperatureThreshold and clearing it once cooled down
+func SetTemperature(oltId int, intfId uint32, onuId uint32, instance uint16, celsius float64) error {
+	if min, max := fixedPointRange(TemperatureStep, 2); celsius < min || celsius > max {
+		return fmt.Errorf("Invalid temperature %.2fC", celsius)
+	}
+
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer unlockAndNotify()
+	state, err := getCircuitPack(key, instance)
+	if err != nil {
+		return err
+	}
+
+	telemetry := state.circuitPackTelemetry(instance)
+	telemetry.Temperature = celsius
+	state.telemetry[instance] = telemetry
+	state.setAlarm(key, CircuitPack, instance, CircuitPackTemperatureYellowAlarm, celsius > HighTemperatureThreshold)
+	return nil
+}
+
+// SetSupplyVoltage sets the supply voltage (in V) of a circuit pack of an ONU
+func SetSupplyVoltage(oltId int, intfId uint32, onuId uint32, instance uint16, volts float64) error {
+	if _, max := fixedPointRange(SupplyVoltageStep, 2); volts < 0 || volts > max {
+		return fmt.Errorf("Invalid supply voltage %.2fV", volts)
+	}
+
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	state, err := getCircuitPack(key, instance)
+	if err != nil {
+		return err
+	}
+
+	telemetry := state.circuitPackTelemetry(instance)
+	telemetry.SupplyVoltage = volts
+	state.telemetry[instance] = telemetry
+	return nil
+}
+
+// SetLaserBias sets the laser bias current (in mA) of a circuit pack of an ONU
+func SetLaserBias(oltId int, intfId uint32, onuId uint32, instance uint16, milliamps float64) error {
+	// The current is encoded on 15 bits
+	if _, max := fixedPointRange(LaserBiasStep, 2); milliamps < 0 || milliamps > max {
+		return fmt.Errorf("Invalid laser bias current %.3fmA", milliamps)
+	}
+
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	state, err := getCircuitPack(key, instance)
+	if err != nil {
+		return err
+	}
+
+	telemetry := state.circuitPackTelemetry(instance)
+	telemetry.LaserBias = milliamps
+	state.telemetry[instance] = telemetry
+	return nil
+}
+
+func getCircuitPack(key OnuKey, instance uint16) (*OnuOmciState, error) {
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", key.IntfId, key.OnuId)
+		return nil, errors.New(errmsg)
+	}
+	if _, ok := state.getMe(CircuitPack, instance); !ok {
+		return nil, fmt.Errorf("Circuit pack %#04x not found", instance)
+	}
+	return state, nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+)
+
+func TestCircuitPackHighTemperature(t *testing.T) {
+	onu := newTestOnu(t)
+
+	if err := SetTemperature(0, onu.intfId, onu.onuId, PonCircuitPack, 90); err != nil {
+		t.Fatal(err)
+	}
+	msg := onu.notification()
+	if msg.Type != AlarmRaised || !alarmRaised(msg.Packet, CircuitPackTemperatureYellowAlarm) {
+		t.Errorf("Got %s %x, expected the temperature yellow alarm raised", msg.Type, msg.Packet)
+	}
+	if telemetry, err := GetCircuitPackTelemetry(0, onu.intfId, onu.onuId, PonCircuitPack); err != nil || telemetry.Temperature != 90 {
+		t.Errorf("Temperature is %.2f C (%v), expected 90", telemetry.Temperature, err)
+	}
+
+	// Still too hot, the alarm is not raised again
+	if err := SetTemperature(0, onu.intfId, onu.onuId, PonCircuitPack, 88); err != nil {
+		t.Fatal(err)
+	}
+	onu.expectNoNotification()
+
+	if err := SetTemperature(0, onu.intfId, onu.onuId, PonCircuitPack, 50); err != nil {
+		t.Fatal(err)
+	}
+	if msg := onu.notification(); msg.Type != AlarmCleared || alarmRaised(msg.Packet, CircuitPackTemperatureYellowAlarm) {
+		t.Errorf("Got %s %x, expected the temperature yellow alarm cleared", msg.Type, msg.Packet)
+	}
+}
+
+func TestCircuitPackSupplyVoltageLaserBias(t *testing.T) {
+	onu := newTestOnu(t)
+	if err := SetSupplyVoltage(0, onu.intfId, onu.onuId, PonCircuitPack, 3.1); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLaserBias(0, onu.intfId, onu.onuId, PonCircuitPack, 35.5); err != nil {
+		t.Fatal(err)
+	}
+
+	// The self test of the ANI-G reports the telemetry of the PON circuit pack
+	resp := onu.send(Test, ANIG, 0x8001, []byte{0x07})
+	if volts := DecodeFixedPoint(resp[23:25], SupplyVoltageStep); volts != 3.1 {
+		t.Errorf("Power feed voltage is %.2fV, expected 3.1V", volts)
+	}
+	if milliamps := DecodeFixedPoint(resp[32:34], LaserBiasStep); milliamps != 35.5 {
+		t.Errorf("Laser bias current is %.3fmA, expected 35.5mA", milliamps)
+	}
+	if celsius := DecodeFixedPoint(resp[35:37], TemperatureStep); celsius != defaultCircuitPackTelemetry.Temperature {
+		t.Errorf("Temperature is %.2f C, expected the default %.2f C", celsius, defaultCircuitPackTelemetry.Temperature)
+	}
+
+	if err := SetLaserBias(0, onu.intfId, onu.onuId, PonCircuitPack, 100); err == nil {
+		t.Error("SetLaserBias of 100mA, above the 15 bits encoding, succeeded")
+	}
+	if err := SetTemperature(0, onu.intfId, onu.onuId, PonCircuitPack, 200); err == nil {
+		t.Error("SetTemperature of 200 C, above the 2 bytes encoding, succeeded")
+	}
+	if telemetry, _ := GetCircuitPackTelemetry(0, onu.intfId, onu.onuId, PonCircuitPack); telemetry.LaserBias != 35.5 {
+		t.Errorf("Laser bias current is %.3fmA after an invalid one, expected 35.5mA", telemetry.LaserBias)
+	}
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"encoding/binary"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 )
@@ -38,61 +39,136 @@ func (k OnuKey) String() string {
 	return fmt.Sprintf("Onu {intfid:%d, onuid:%d}", k.IntfId, k.OnuId)
 }
 
-func GetAttributes(class OmciClass, content OmciContent, key OnuKey, pkt []byte) []byte {
+func GetAttributes(class OmciClass, instance uint16, content OmciContent, key OnuKey, pkt []byte) []byte {
 	log.WithFields(log.Fields{
 		"IntfId": key.IntfId,
 		"OnuId": key.OnuId,
 	}).Tracef("GetAttributes() invoked")
 
-	switch class {
-	case ANIG:
-		pos := uint(11)
-		pkt, _ = GetANIGAttributes(&pos, pkt, content)
+	if _, ok := MeDefinitions[class]; ok {
+		mask := uint16(getAttributeMask(content))
+		unlock := lockForGet(class, mask)
+		state := OnuOmciStateMap[key]
+		if instance == WildcardInstance && Config.AllowWildcardInstance {
+			if first, ok := state.firstMeInstance(class); ok {
+				// The response carries the instance which was read
+				instance = first
+				pkt[6] = byte(instance >> 8)
+				pkt[7] = byte(instance & 0xFF)
+			}
+		}
+		result := state.getMeAttributes(class, instance, mask, pkt)
+		unlock()
+		pkt[8] = byte(result)
 		return pkt
+	}
 
-	case SoftwareImage:
-		pos := uint(11)
-		pkt, _ = GetSoftwareImageAttributes(&pos, pkt, content)
-		return pkt
+	if get := legacyGetter(class, instance, key); get != nil {
+		return legacyGetAttributes(pkt, content, get)
+	}
 
-	case ONUG:
-		pos := uint(11)
-		pkt, _ = GetOnuGAttributes(&pos, pkt, content, key)
+	if !isClassSupported(class) {
+		log.WithFields(log.Fields{
+			"IntfId": key.IntfId,
+			"OnuId": key.OnuId,
+			"class": class,
+		}).Warnf("Unknown ME Class: %v", class)
+		pkt[8] = byte(UnknownEntity)
+		pkt[9] = 0x00
+		pkt[10] = 0x00
 		return pkt
+	}
+
+	// For unimplemented MEs, just fill in the attribute mask and return 0 values for the requested attributes
+	// TODO implement Get for unimplemented MEs as well
+	log.WithFields(log.Fields{
+		"IntfId": key.IntfId,
+		"OnuId": key.OnuId,
+		"class": class,
+	}).Tracef("Unimplemeted GetAttributes for ME Class: %v " +
+	    "Filling with zero value for the requested attributes", class)
+	AttributesMask := getAttributeMask(content)
+	pkt[8] = 0x00 // Command Processed Successfully
+	pkt[9] = uint8(AttributesMask >> 8)
+	pkt[10] = uint8(AttributesMask & 0xFF)
+
+	return pkt
+}
 
+// legacyGetter returns the function filling in the attributes of the MEs not in MeDefinitions
+// which are served by a Get<ME>Attributes function, or nil
+func legacyGetter(class OmciClass, instance uint16, key OnuKey) func(pos *uint, buf []byte, content OmciContent) {
+	switch class {
+	case SoftwareImage:
+		return func(pos *uint, buf []byte, c OmciContent) {
+			GetSoftwareImageAttributes(pos, buf, c, key, instance)
+		}
+	case ONUG:
+		return func(pos *uint, buf []byte, c OmciContent) {
+			GetOnuGAttributes(pos, buf, c, key)
+		}
 	case ONU2G:
-		pos := uint(11)
-		pkt, _ = GetOnu2GAttributes(&pos, pkt, content)
-		return pkt
+		return func(pos *uint, buf []byte, c OmciContent) {
+			GetOnu2GAttributes(pos, buf, c, key)
+		}
+	}
+	return nil
+}
 
-	case EthernetPMHistoryData:
-		pos := uint(11)
-		pkt, _ = GetEthernetPMHistoryDataAttributes(&pos, pkt, content)
-		return pkt
+// legacyGetBufferLength leaves room for any attribute of the MEs served by the Get<ME>Attributes functions
+const legacyGetBufferLength = BaselineFrameLength + 25
 
-	default:
-		// For unimplemented MEs, just fill in the attribute mask and return 0 values for the requested attributes
-		// TODO implement Get for unimplemented MEs as well
-		log.WithFields(log.Fields{
-			"IntfId": key.IntfId,
-			"OnuId": key.OnuId,
-			"class": class,
-		}).Tracef("Unimplemeted GetAttributes for ME Class: %v " +
-		    "Filling with zero value for the requested attributes", class)
-		AttributesMask := getAttributeMask(content)
-		pkt[8] = 0x00 // Command Processed Successfully
-		pkt[9] = uint8(AttributesMask >> 8)
-		pkt[10] = uint8(AttributesMask & 0xFF)
+// legacyGetAttributes fills pkt with the requested attributes using a Get<ME>Attributes function
+func legacyGetAttributes(pkt []byte, content OmciContent, get func(pos *uint, buf []byte, content OmciContent)) []byte {
+	mask := uint16(getAttributeMask(content))
+	values, served, unsupported, failed := legacyReadAttributes(mask, getAttributesEnd-getAttributesStart, get)
+	copy(pkt[getAttributesStart:], values)
 
-		return pkt
+	pkt[8] = byte(Success)
+	if unsupported != 0 || failed != 0 {
+		pkt[8] = byte(AttributeFailure)
+	}
+	binary.BigEndian.PutUint16(pkt[9:11], served)
+	binary.BigEndian.PutUint16(pkt[getAttributesEnd:getAttributesEnd+2], unsupported)
+	binary.BigEndian.PutUint16(pkt[getAttributesEnd+2:getAttributesEnd+4], failed)
+	return pkt
+}
+
+// legacyReadAttributes reads the attributes in mask one at a time using a Get<ME>Attributes function, so
+// that those which don't fit in limit bytes are reported in the attribute execution mask, and those
+// without a handler in the unsupported attribute mask
+func legacyReadAttributes(mask uint16, limit int,
+	get func(pos *uint, buf []byte, content OmciContent)) (values []byte, served, unsupported, failed uint16) {
+	for index := 1; index <= 16; index++ {
+		bit := attributeMaskBit(index)
+		if mask&bit == 0 {
+			continue
+		}
+
+		var single OmciContent
+		binary.BigEndian.PutUint16(single[0:2], bit)
+		buf := make([]byte, legacyGetBufferLength)
+		end := uint(getAttributesStart)
+		get(&end, buf, single)
+		if binary.BigEndian.Uint16(buf[9:11]) == 0 {
+			unsupported |= bit
+			continue
+		}
+		if len(values)+int(end-getAttributesStart) > limit {
+			failed |= bit
+			continue
+		}
+		values = append(values, buf[getAttributesStart:end]...)
+		served |= bit
 	}
+	return values, served, unsupported, failed
 }
 
 func getAttributeMask(content OmciContent) int {
 	// mask is present in pkt[8] and pkt[9]
+	mask := NewContentReader(content[:]).ReadMask()
 	log.WithFields(log.Fields{
-		"content[0]": content[0],
-		"content[1]": content[1],
+		"mask": fmt.Sprintf("%04x", mask),
 	}).Tracef("GetAttributeMask() invoked")
-	return (int(content[0]) << 8) | int(content[1])
+	return int(mask)
 }
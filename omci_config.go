@@ -0,0 +1,150 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+)
+
+// OmciSimConfig holds the settings changing how the simulator answers the OLT,
+// it is expected to be filled in before the first OMCI message is processed
+type OmciSimConfig struct {
+	// StrictMode answers requests on ME classes the simulator does not model with
+	// "unknown managed entity" instead of a zero-filled success
+	StrictMode bool
+	// SupportedClasses are the unmodeled ME classes still answered with zero values in StrictMode
+	SupportedClasses []OmciClass
+	// RejectBaselineTableSet answers a baseline message set Set writing a table attribute with
+	// "not supported", for OLTs expected to write tables with the extended message set
+	RejectBaselineTableSet bool
+	// CascadeDelete deletes the MEs pointing to a deleted ME, such as the ports of a MAC bridge,
+	// instead of answering device busy while they exist
+	CascadeDelete bool
+	// DropZeroTransactionId drops the requests with transaction id 0, reserved for the autonomous
+	// messages, instead of answering them with a warning
+	DropZeroTransactionId bool
+	// CheckMibUpload makes every MibUploadNext of a modeled ME fail if the attribute values it reports
+	// differ from the ones a Get of the ME returns, to catch a MIB upload out of sync with the ME definitions
+	CheckMibUpload bool
+	// AllowWildcardInstance answers the Gets of instance WildcardInstance with the instance of the ME class
+	// having the lowest id, or unknown instance if there is none
+	AllowWildcardInstance bool
+	// ResponseDeviceId, if set, is the device identifier of every response instead of the one of the request
+	ResponseDeviceId uint8
+	// ValidateGemPortDirection rejects GEM Port Network CTPs whose pointers contradict their direction
+	ValidateGemPortDirection bool
+	// NumPotsUni is the number of POTS UNIs of the ONU, instances 0x0101 onwards
+	NumPotsUni int
+	// PortTypes are the types, as in the Circuit Pack type, of the physical ports reported by the
+	// Port Mapping Package, 4 10/100/1000 Ethernet ports by default
+	PortTypes []uint8
+	// NumIpHost is the number of IP hosts of the ONU, instances 0x0001 onwards
+	NumIpHost int
+	// NumVeip is the number of Virtual Ethernet Interface Points of the ONU, instances 0x0501 onwards
+	NumVeip int
+	// OnuGSurvivalTime, OnuGLogicalOnuId and OnuGLogicalPassword are the values reported by the ONU-G until
+	// the OLT sets the logical ONU id and password, the strings are up to 24 characters
+	OnuGSurvivalTime    uint8
+	OnuGLogicalOnuId    string
+	OnuGLogicalPassword string
+	// Onu3GFlashMemoryPerformance and Onu3GLatestRestartReason are the values reported by the ONU3-G
+	Onu3GFlashMemoryPerformance uint8
+	Onu3GLatestRestartReason    uint8
+}
+
+var Config = OmciSimConfig{}
+
+// isClassSupported reports whether a request on an unmodeled ME class may be answered
+func isClassSupported(class OmciClass) bool {
+	if !Config.StrictMode {
+		return true
+	}
+	for _, c := range Config.SupportedClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// OnuConfig holds the identity reported by a single ONU, empty values keep the simulator defaults
+type OnuConfig struct {
+	// EquipmentId is the ONU2-G equipment id, up to 20 characters
+	EquipmentId string
+	// Version is the ONU-G version, up to 14 characters
+	Version string
+	// SoftwareVersions are the versions of the software images in slot 0 and 1, up to 14 characters
+	SoftwareVersions [2]string
+}
+
+const (
+	equipmentIdLength     = 20
+	versionLength         = 14
+	softwareVersionLength = 14
+)
+
+// onuConfigs are the ONU configs set before the OMCI state of the ONUs is created
+var onuConfigs = map[OnuKey]OnuConfig{}
+
+// SetOnuConfig sets the identity reported by an ONU, it is applied when the OMCI state
+// of the ONU is created, or right away if the ONU already exists
+func SetOnuConfig(oltId int, intfId uint32, onuId uint32, config OnuConfig) error {
+	if len(config.EquipmentId) > equipmentIdLength {
+		return fmt.Errorf("Equipment id %q is longer than %d characters", config.EquipmentId, equipmentIdLength)
+	}
+	if len(config.Version) > versionLength {
+		return fmt.Errorf("Version %q is longer than %d characters", config.Version, versionLength)
+	}
+	for slot, version := range config.SoftwareVersions {
+		if len(version) > softwareVersionLength {
+			return fmt.Errorf("Software version %q of slot %d is longer than %d characters", version, slot, softwareVersionLength)
+		}
+	}
+
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	onuConfigs[key] = config
+	if state, ok := OnuOmciStateMap[key]; ok {
+		state.config = config
+	}
+	return nil
+}
+
+// getOnuConfig returns the config of an ONU, the default one if the ONU is unknown
+func getOnuConfig(key OnuKey) OnuConfig {
+	OnuOmciStateMapLock.RLock()
+	defer OnuOmciStateMapLock.RUnlock()
+	if state, ok := OnuOmciStateMap[key]; ok {
+		return state.config
+	}
+	return OnuConfig{}
+}
+
+// putConfigString writes a configured string padded with nulls to size, or def if the string is empty
+func putConfigString(pos *uint, pkt []byte, value string, size int, def []byte) {
+	field := make([]byte, size)
+	if value != "" {
+		copy(field, value)
+	} else {
+		copy(field, def)
+	}
+	for _, ch := range field {
+		pkt[*pos] = ch
+		*pos++
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+// unmodeledClass is an ME class the simulator has no definition of
+const unmodeledClass OmciClass = 65
+
+func TestGetUnknownClass(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	onu := newTestOnu(t)
+
+	Config.StrictMode = true
+	if result, _ := onu.get(unmodeledClass, 1, 0x8000); result != UnknownEntity {
+		t.Errorf("Strict Get got result %d, expected %d", result, UnknownEntity)
+	}
+
+	Config.SupportedClasses = []OmciClass{unmodeledClass}
+	if result, _ := onu.get(unmodeledClass, 1, 0x8000); result != Success {
+		t.Errorf("Strict Get of a supported class got result %d, expected %d", result, Success)
+	}
+
+	Config.StrictMode = false
+	Config.SupportedClasses = nil
+	if result, _ := onu.get(unmodeledClass, 1, 0x8000); result != Success {
+		t.Errorf("Get got result %d, expected %d", result, Success)
+	}
+}
+
+func TestOnuConfigPerOnu(t *testing.T) {
+	onus := []*testOnu{newTestOnu(t), newTestOnu(t)}
+	configs := []OnuConfig{
+		{EquipmentId: "SIM-ONU-A", Version: "v1.0", SoftwareVersions: [2]string{"1.0.0", "1.0.1"}},
+		{EquipmentId: "SIM-ONU-B", Version: "v2.0", SoftwareVersions: [2]string{"2.0.0", "2.0.1"}},
+	}
+	for i, onu := range onus {
+		if err := SetOnuConfig(0, onu.intfId, onu.onuId, configs[i]); err != nil {
+			t.Fatalf("SetOnuConfig failed: %s", err)
+		}
+	}
+
+	field := func(value string, size int) []byte {
+		b := make([]byte, size)
+		copy(b, value)
+		return b
+	}
+	for i, onu := range onus {
+		if version := onu.mustGet(ONUG, 0, 0x4000)[:versionLength]; !bytes.Equal(version, field(configs[i].Version, versionLength)) {
+			t.Errorf("ONU %d version is %q, expected %q", i, version, configs[i].Version)
+		}
+		if id := onu.mustGet(ONU2G, 0, 0x8000)[:equipmentIdLength]; !bytes.Equal(id, field(configs[i].EquipmentId, equipmentIdLength)) {
+			t.Errorf("ONU %d equipment id is %q, expected %q", i, id, configs[i].EquipmentId)
+		}
+		for slot, expected := range configs[i].SoftwareVersions {
+			version := onu.mustGet(SoftwareImage, uint16(slot), 0x8000)[:softwareVersionLength]
+			if !bytes.Equal(version, field(expected, softwareVersionLength)) {
+				t.Errorf("ONU %d software version of slot %d is %q, expected %q", i, slot, version, expected)
+			}
+		}
+	}
+}
+
+func TestOnuConfigTooLong(t *testing.T) {
+	onu := newTestOnu(t)
+	config := OnuConfig{Version: "a version longer than 14 characters"}
+	if err := SetOnuConfig(0, onu.intfId, onu.onuId, config); err == nil {
+		t.Error("SetOnuConfig of a version longer than 14 characters succeeded")
+	}
+}
+
+func TestResponseDeviceId(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	onu := newTestOnu(t)
+
+	if resp := onu.send(Get, ONUG, 0, []byte{0x80, 0x00}); resp[3] != BaselineDeviceId {
+		t.Errorf("Response device id is %#02x, expected the one of the request %#02x", resp[3], BaselineDeviceId)
+	}
+
+	Config.ResponseDeviceId = 0x0a
+	if resp := onu.send(Get, ONUG, 0, []byte{0x80, 0x00}); resp[3] != 0x0a {
+		t.Errorf("Response device id is %#02x, expected 0x0a", resp[3])
+	}
+}
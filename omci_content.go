@@ -0,0 +1,87 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ContentReader decodes the fields carried in the contents of an OMCI message,
+// keeping track of the offset of the next field to read
+type ContentReader struct {
+	content []byte
+	offset  int
+}
+
+func NewContentReader(content []byte) *ContentReader {
+	return &ContentReader{content: content}
+}
+
+// Offset returns the offset of the next field to read
+func (r *ContentReader) Offset() int {
+	return r.offset
+}
+
+// Remaining returns the number of bytes left to read
+func (r *ContentReader) Remaining() int {
+	return len(r.content) - r.offset
+}
+
+// ReadMask reads the 2-byte attribute mask found at the start of Get and Set contents
+func (r *ContentReader) ReadMask() uint16 {
+	mask, err := r.ReadUint16()
+	if err != nil {
+		return 0
+	}
+	return mask
+}
+
+func (r *ContentReader) ReadUint8() (uint8, error) {
+	b, err := r.ReadBytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *ContentReader) ReadUint16() (uint16, error) {
+	b, err := r.ReadBytes(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
+
+func (r *ContentReader) ReadUint32() (uint32, error) {
+	b, err := r.ReadBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+// ReadBytes returns the next n bytes of the contents, or an error if fewer are left
+func (r *ContentReader) ReadBytes(n int) ([]byte, error) {
+	if n < 0 || n > r.Remaining() {
+		return nil, &OmciError{fmt.Sprintf("Content overrun: cannot read %d bytes at offset %d of %d",
+			n, r.offset, len(r.content))}
+	}
+	b := r.content[r.offset : r.offset+n]
+	r.offset += n
+	return b, nil
+}
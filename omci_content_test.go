@@ -0,0 +1,68 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+)
+
+func TestContentReaderGemPortCreate(t *testing.T) {
+	pkt := EncodeCreate(1, GEMPortNetworkCTP, 0x0401, map[int][]byte{
+		GemPortCtpPortId:       {0x04, 0x01},
+		GemPortCtpTcontPointer: {0x80, 0x01},
+		GemPortCtpDirection:    {GemPortBidirectional},
+	})
+	_, _, _, _, _, content, err := ParsePkt(pkt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The set-by-create attributes follow each other in attribute order
+	r := NewContentReader(content[:])
+	portId, err := r.ReadUint16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if portId != 0x0401 {
+		t.Errorf("Port-ID is %#04x, expected 0x0401", portId)
+	}
+	tcont, _ := r.ReadUint16()
+	direction, _ := r.ReadUint8()
+	if tcont != 0x8001 || direction != GemPortBidirectional {
+		t.Errorf("T-CONT pointer %#04x and direction %d, expected 0x8001 and %d", tcont, direction, GemPortBidirectional)
+	}
+	if r.Offset() != 5 {
+		t.Errorf("Offset is %d, expected 5", r.Offset())
+	}
+}
+
+func TestContentReaderOverrun(t *testing.T) {
+	r := NewContentReader([]byte{0x01, 0x02, 0x03})
+	if _, err := r.ReadUint16(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ReadUint32(); err == nil {
+		t.Error("Expected an error reading past the contents")
+	}
+	// A failed read doesn't consume the contents
+	if b, err := r.ReadUint8(); err != nil || b != 0x03 {
+		t.Errorf("Got %#02x, %v, expected 0x03", b, err)
+	}
+	if r.Remaining() != 0 {
+		t.Errorf("%d bytes remaining, expected 0", r.Remaining())
+	}
+}
@@ -31,6 +31,9 @@ const (
 	GemPortAdded ChMessageType = 0
 	UniLinkUp ChMessageType = 1
 	UniLinkDown ChMessageType = 2
+	AlarmRaised ChMessageType = 3
+	AlarmCleared ChMessageType = 4
+	AttributeValueChanged ChMessageType = 5
 )
 
 func (m ChMessageType) String() string {
@@ -38,6 +41,9 @@ func (m ChMessageType) String() string {
 		"GemPortAdded",
 		"UniLinkUp",
 		"UniLinkDown",
+		"AlarmRaised",
+		"AlarmCleared",
+		"AttributeValueChanged",
 	}
 	return names[m]
 }
@@ -154,37 +160,160 @@ const (
 )
 
 
+// OmciResult is the result reason of an OMCI response
+type OmciResult byte
+
+const (
+	Success          OmciResult = 0
+	ProcessingError  OmciResult = 1
+	NotSupported     OmciResult = 2
+	ParameterError   OmciResult = 3
+	UnknownEntity    OmciResult = 4
+	UnknownInstance  OmciResult = 5
+	DeviceBusy       OmciResult = 6
+	InstanceExists   OmciResult = 7
+	AttributeFailure OmciResult = 9
+)
+
 // OMCI Managed Entity Class
 type OmciClass uint16
 
 func (c OmciClass) PrettyPrint() string {
 	switch c {
+	case ONUData:
+		return "ONUData"
+	case CircuitPack:
+		return "CircuitPack"
 	case SoftwareImage:
 		return "SoftwareImage"
+	case PPTPEthernetUNI:
+		return "PPTPEthernetUNI"
 	case EthernetPMHistoryData:
 		return "EthernetPMHistoryData"
+	case MacBridgeServiceProfile:
+		return "MacBridgeServiceProfile"
+	case MacBridgePortConfigData:
+		return "MacBridgePortConfigData"
+	case MacBridgePMHistoryData:
+		return "MacBridgePMHistoryData"
+	case PPTPPotsUNI:
+		return "PPTPPotsUNI"
+	case Ieee8021pMapperServiceProfile:
+		return "Ieee8021pMapperServiceProfile"
+	case IPHostConfigData:
+		return "IPHostConfigData"
+	case TCPUDPConfigData:
+		return "TCPUDPConfigData"
+	case VoIPVoiceCTP:
+		return "VoIPVoiceCTP"
+	case SIPAgentConfigData:
+		return "SIPAgentConfigData"
+	case SIPUserData:
+		return "SIPUserData"
+	case LargeString:
+		return "LargeString"
+	case ExtendedVlanTagging:
+		return "ExtendedVlanTagging"
 	case ONUG:
 		return "ONUG"
 	case ONU2G:
 		return "ONU2G"
+	case TCONT:
+		return "TCONT"
 	case ANIG:
 		return "ANIG"
+	case GEMInterworkingTP:
+		return "GEMInterworkingTP"
 	case GEMPortNetworkCTP:
 		return "GEMPortNetworkCTP"
+	case GalEthernetProfile:
+		return "GalEthernetProfile"
+	case ThresholdData1:
+		return "ThresholdData1"
+	case ThresholdData2:
+		return "ThresholdData2"
+	case TrafficDescriptor:
+		return "TrafficDescriptor"
+	case PseudowireMaintenance:
+		return "PseudowireMaintenance"
+	case OMCI:
+		return "OMCI"
+	case ManagedEntity:
+		return "ManagedEntity"
+	case AttributeME:
+		return "AttributeME"
+	case EthernetPMHistoryData3:
+		return "EthernetPMHistoryData3"
+	case PortMappingPackage:
+		return "PortMappingPackage"
+	case Dot1RateLimiter:
+		return "Dot1RateLimiter"
+	case MulticastOperationsProfile:
+		return "MulticastOperationsProfile"
+	case MulticastSubscriberConfigInfo:
+		return "MulticastSubscriberConfigInfo"
+	case FecPMHistoryData:
+		return "FecPMHistoryData"
+	case VirtualEthernetInterfacePoint:
+		return "VirtualEthernetInterfacePoint"
+	case EthernetFrameExtendedPM:
+		return "EthernetFrameExtendedPM"
+	case EthernetFrameExtendedPM64Bit:
+		return "EthernetFrameExtendedPM64Bit"
+	case ONU3G:
+		return "ONU3G"
+	case EnhancedFecPMHistoryData:
+		return "EnhancedFecPMHistoryData"
 	default:
 		log.Tracef("Cant't convert OmciClass %v to string", c)
-		return string(c)
+		return fmt.Sprintf("%d", c)
 	}
 }
 
 const (
 	// Managed Entity Class values
-	SoftwareImage         OmciClass = 7
-	EthernetPMHistoryData OmciClass = 24
-	ONUG                  OmciClass = 256
-	ONU2G                 OmciClass = 257
-	ANIG                  OmciClass = 263
-	GEMPortNetworkCTP     OmciClass = 268
+	ONUData                       OmciClass = 2
+	CircuitPack                   OmciClass = 6
+	SoftwareImage                 OmciClass = 7
+	PPTPEthernetUNI               OmciClass = 11
+	EthernetPMHistoryData         OmciClass = 24
+	MacBridgeServiceProfile       OmciClass = 45
+	MacBridgePortConfigData       OmciClass = 47
+	MacBridgePMHistoryData        OmciClass = 51
+	PPTPPotsUNI                   OmciClass = 53
+	Ieee8021pMapperServiceProfile OmciClass = 130
+	IPHostConfigData              OmciClass = 134
+	TCPUDPConfigData              OmciClass = 136
+	VoIPVoiceCTP                  OmciClass = 139
+	SIPAgentConfigData            OmciClass = 150
+	SIPUserData                   OmciClass = 153
+	LargeString                   OmciClass = 157
+	ExtendedVlanTagging           OmciClass = 171
+	ONUG                          OmciClass = 256
+	ONU2G                         OmciClass = 257
+	TCONT                         OmciClass = 262
+	ANIG                          OmciClass = 263
+	GEMInterworkingTP             OmciClass = 266
+	GEMPortNetworkCTP             OmciClass = 268
+	GalEthernetProfile            OmciClass = 272
+	ThresholdData1                OmciClass = 273
+	ThresholdData2                OmciClass = 274
+	TrafficDescriptor             OmciClass = 280
+	PseudowireMaintenance         OmciClass = 284
+	OMCI                          OmciClass = 287
+	ManagedEntity                 OmciClass = 288
+	AttributeME                   OmciClass = 289
+	EthernetPMHistoryData3        OmciClass = 296
+	PortMappingPackage            OmciClass = 297
+	Dot1RateLimiter               OmciClass = 298
+	MulticastOperationsProfile    OmciClass = 309
+	MulticastSubscriberConfigInfo OmciClass = 310
+	FecPMHistoryData              OmciClass = 312
+	VirtualEthernetInterfacePoint OmciClass = 329
+	EthernetFrameExtendedPM       OmciClass = 334
+	EthernetFrameExtendedPM64Bit  OmciClass = 426
+	ONU3G                         OmciClass = 441
+	EnhancedFecPMHistoryData      OmciClass = 453
 )
 
 // OMCI Message Identifier
@@ -195,6 +324,28 @@ type OmciMessageIdentifier struct {
 
 type OmciContent [32]byte
 
+// AutonomousTransactionId is the transaction id of the messages sent by the ONU on its own,
+// such as the alarms and attribute value changes
+const AutonomousTransactionId uint16 = 0
+
+// WildcardInstance is the instance some OLTs Get to read the first instance of an ME class,
+// see Config.AllowWildcardInstance
+const WildcardInstance uint16 = 0xFFFF
+
+const (
+	// Device identifiers of the baseline and extended message sets
+	BaselineDeviceId uint8 = 0x0A
+	ExtendedDeviceId uint8 = 0x0B
+
+	// BaselineFrameLength is the length of a baseline frame, including the trailer
+	BaselineFrameLength = 48
+
+	// Extended frames carry a 2-byte contents length after the 8-byte header and end with a 4-byte MIC
+	ExtendedHeaderLength      = 10
+	ExtendedMicLength         = 4
+	ExtendedMaxContentsLength = 1966
+)
+
 type OmciMessage struct {
 	TransactionId uint16
 	MessageType   OmciMsgType
@@ -206,6 +357,11 @@ type OmciMessage struct {
 func ParsePkt(pkt []byte) (uint16, uint8, OmciMsgType, OmciClass, uint16, OmciContent, error) {
 	var m OmciMessage
 
+	// Extended frames are only as long as their contents
+	if len(pkt) >= 4 && pkt[3] == ExtendedDeviceId && len(pkt) < binary.Size(m) {
+		pkt = append(append([]byte{}, pkt...), make([]byte, binary.Size(m)-len(pkt))...)
+	}
+
 	r := bytes.NewReader(pkt)
 
 	if err := binary.Read(r, binary.BigEndian, &m); err != nil {
@@ -233,3 +389,39 @@ func ParsePkt(pkt []byte) (uint16, uint8, OmciMsgType, OmciClass, uint16, OmciCo
 
 	return m.TransactionId, m.DeviceId, m.MessageType & 0x1F, m.MessageId.Class, m.MessageId.Instance, m.Content, nil
 }
+
+// SplitFrames splits a buffer containing back-to-back baseline and/or extended OMCI frames
+// into the individual frames, using the device identifier (and for extended frames the
+// contents length) of each frame to find where it ends
+func SplitFrames(buf []byte) ([][]byte, error) {
+	var frames [][]byte
+
+	for offset := 0; offset < len(buf); {
+		remaining := len(buf) - offset
+		if remaining < 4 {
+			return nil, fmt.Errorf("Truncated OMCI frame at offset %d: %d bytes left", offset, remaining)
+		}
+
+		var length int
+		switch buf[offset+3] {
+		case BaselineDeviceId:
+			length = BaselineFrameLength
+		case ExtendedDeviceId:
+			if remaining < ExtendedHeaderLength {
+				return nil, fmt.Errorf("Truncated OMCI frame at offset %d: %d bytes left", offset, remaining)
+			}
+			contentsLength := int(binary.BigEndian.Uint16(buf[offset+8 : offset+10]))
+			length = ExtendedHeaderLength + contentsLength + ExtendedMicLength
+		default:
+			return nil, fmt.Errorf("Invalid device identifier 0x%02x at offset %d", buf[offset+3], offset)
+		}
+
+		if remaining < length {
+			return nil, fmt.Errorf("Truncated OMCI frame at offset %d: expected %d bytes, %d left", offset, length, remaining)
+		}
+		frames = append(frames, buf[offset:offset+length])
+		offset += length
+	}
+
+	return frames, nil
+}
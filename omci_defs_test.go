@@ -0,0 +1,66 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitFrames(t *testing.T) {
+	first := EncodeRequest(1, Get, ONUG, 0, []byte{0x80, 0x00})
+	second := EncodeRequest(2, Get, ONU2G, 0, []byte{0x80, 0x00})
+
+	frames, err := SplitFrames(append(append([]byte{}, first...), second...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("Got %d frames, expected 2", len(frames))
+	}
+	if !bytes.Equal(frames[0], first) || !bytes.Equal(frames[1], second) {
+		t.Errorf("Got frames %x and %x, expected %x and %x", frames[0], frames[1], first, second)
+	}
+}
+
+func TestSplitFramesExtended(t *testing.T) {
+	baseline := EncodeRequest(1, Get, ONUG, 0, []byte{0x80, 0x00})
+	extended := EncodeExtendedRequest(2, Get, ANIG, 0x8001, []byte{0xff, 0xff})
+
+	frames, err := SplitFrames(append(append([]byte{}, extended...), baseline...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 || !bytes.Equal(frames[0], extended) || !bytes.Equal(frames[1], baseline) {
+		t.Errorf("Got frames %x, expected %x and %x", frames, extended, baseline)
+	}
+}
+
+func TestSplitFramesPartial(t *testing.T) {
+	first := EncodeRequest(1, Get, ONUG, 0, []byte{0x80, 0x00})
+	second := EncodeRequest(2, Get, ONU2G, 0, []byte{0x80, 0x00})
+
+	for name, buf := range map[string][]byte{
+		"truncated frame":  append(append([]byte{}, first...), second[:20]...),
+		"truncated header": append(append([]byte{}, first...), second[:3]...),
+		"bad device id":    append(append([]byte{}, first...), 0x00, 0x02, 0x49, 0x0c),
+	} {
+		if frames, err := SplitFrames(buf); err == nil {
+			t.Errorf("%s: got frames %x, expected an error", name, frames)
+		}
+	}
+}
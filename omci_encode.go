@@ -0,0 +1,72 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+)
+
+// EncodeRequest builds a baseline OMCI request, with the acknowledge request bit set,
+// content longer than the baseline contents is truncated
+func EncodeRequest(txId uint16, msgType OmciMsgType, class OmciClass, instance uint16, content []byte) []byte {
+	pkt := make([]byte, BaselineFrameLength)
+	binary.BigEndian.PutUint16(pkt[0:2], txId)
+	pkt[2] = 0x40 | byte(msgType)
+	pkt[3] = BaselineDeviceId
+	binary.BigEndian.PutUint16(pkt[4:6], uint16(class))
+	binary.BigEndian.PutUint16(pkt[6:8], instance)
+	copy(pkt[8:40], content)
+	return pkt
+}
+
+// EncodeExtendedRequest builds an extended message set OMCI request, its contents can be up to
+// ExtendedMaxContentsLength bytes long
+func EncodeExtendedRequest(txId uint16, msgType OmciMsgType, class OmciClass, instance uint16, content []byte) []byte {
+	pkt := make([]byte, ExtendedHeaderLength+len(content)+ExtendedMicLength)
+	binary.BigEndian.PutUint16(pkt[0:2], txId)
+	pkt[2] = 0x40 | byte(msgType)
+	pkt[3] = ExtendedDeviceId
+	binary.BigEndian.PutUint16(pkt[4:6], uint16(class))
+	binary.BigEndian.PutUint16(pkt[6:8], instance)
+	binary.BigEndian.PutUint16(pkt[8:10], uint16(len(content)))
+	copy(pkt[ExtendedHeaderLength:], content)
+	return pkt
+}
+
+// EncodeCreate builds a Create request for an ME class in MeDefinitions, packing the set-by-create
+// attributes found in attrs (indexed by attribute number) in attribute order. The set-by-create
+// attributes missing from attrs take their default value. It returns nil for an unknown class.
+func EncodeCreate(txId uint16, class OmciClass, instance uint16, attrs map[int][]byte) []byte {
+	def, ok := MeDefinitions[class]
+	if !ok {
+		return nil
+	}
+
+	var content []byte
+	for i, attrDef := range def.Attributes {
+		if attrDef.Access&AttrSetByCreate == 0 {
+			continue
+		}
+		value := attrDef.defaultValue()
+		if v, ok := attrs[i+1]; ok {
+			value = make([]byte, attrDef.Size)
+			copy(value, v)
+		}
+		content = append(content, value...)
+	}
+	return EncodeRequest(txId, Create, class, instance, content)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestEncodeCreate(t *testing.T) {
+	pkt := EncodeCreate(0x1234, GEMPortNetworkCTP, 0x0401, map[int][]byte{
+		GemPortCtpPortId:       {0x04, 0x01},
+		GemPortCtpTcontPointer: {0x80, 0x01},
+		GemPortCtpDirection:    {GemPortBidirectional},
+		// Not set-by-create, left out
+		GemPortCtpEncryptionState: {0x01},
+	})
+
+	header := []byte{0x12, 0x34, 0x40 | byte(Create), BaselineDeviceId, 0x01, 0x0c, 0x04, 0x01}
+	if !bytes.Equal(pkt[:8], header) {
+		t.Errorf("Header is %x, expected %x", pkt[:8], header)
+	}
+	// The set-by-create attributes in attribute order, the missing ones take their default value
+	content := []byte{0x04, 0x01, 0x80, 0x01, GemPortBidirectional, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	if !bytes.Equal(pkt[8:8+len(content)], content) {
+		t.Errorf("Contents are %x, expected %x", pkt[8:8+len(content)], content)
+	}
+
+	if EncodeCreate(1, unmodeledClass, 1, nil) != nil {
+		t.Error("Expected no request for an unmodeled class")
+	}
+}
+
+func TestEncodeCreateRoundTrip(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+
+	mask := attributeMaskBit(GemPortCtpPortId) | attributeMaskBit(GemPortCtpTcontPointer) | attributeMaskBit(GemPortCtpDirection)
+	values := onu.mustGet(GEMPortNetworkCTP, 0x0401, mask)
+	if portId, tcont := binary.BigEndian.Uint16(values[0:2]), binary.BigEndian.Uint16(values[2:4]); portId != 0x0401 || tcont != 0x8001 {
+		t.Errorf("Port-ID %#04x and T-CONT pointer %#04x, expected 0x0401 and 0x8001", portId, tcont)
+	}
+	if values[4] != GemPortBidirectional {
+		t.Errorf("Direction is %d, expected %d", values[4], GemPortBidirectional)
+	}
+	if result := onu.create(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401)); result != InstanceExists {
+		t.Errorf("Second Create got result %d, expected %d", result, InstanceExists)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"math"
+)
+
+// Granularity of the fixed-point attribute encodings
+const (
+	OpticalLevelStep   = 0.002 // dB, optical signal and transmit optical levels
+	PowerThresholdStep = 0.5   // dB, transmit power thresholds
+)
+
+// EncodeSigned encodes v as a big-endian two's complement integer of size bytes,
+// saturating it to the range of that size
+func EncodeSigned(v int64, size int) []byte {
+	max := int64(1)<<uint(8*size-1) - 1
+	min := -max - 1
+	if v > max {
+		v = max
+	} else if v < min {
+		v = min
+	}
+
+	b := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+// DecodeSigned decodes a big-endian two's complement integer
+func DecodeSigned(b []byte) int64 {
+	if len(b) == 0 {
+		return 0
+	}
+	v := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		v = v<<8 | int64(c)
+	}
+	return v
+}
+
+// EncodeFixedPoint encodes value in units of step as a two's complement integer of size bytes
+func EncodeFixedPoint(value float64, step float64, size int) []byte {
+	return EncodeSigned(int64(math.Round(value/step)), size)
+}
+
+// fixedPointRange returns the lowest and highest values EncodeFixedPoint encodes in units of step
+// on size bytes without saturating them
+func fixedPointRange(step float64, size int) (float64, float64) {
+	max := int64(1)<<uint(8*size-1) - 1
+	return float64(-max-1) * step, float64(max) * step
+}
+
+// DecodeFixedPoint decodes a two's complement integer in units of step
+func DecodeFixedPoint(b []byte, step float64) float64 {
+	return float64(DecodeSigned(b)) * step
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeSigned(t *testing.T) {
+	tests := []struct {
+		value    int64
+		size     int
+		expected []byte
+	}{
+		{-1, 2, []byte{0xff, 0xff}},
+		{-8108, 2, []byte{0xe0, 0x54}},
+		{3171, 2, []byte{0x0c, 0x63}},
+		{-127, 1, []byte{0x81}},
+		// Saturated to the range of the size
+		{40000, 2, []byte{0x7f, 0xff}},
+		{-40000, 2, []byte{0x80, 0x00}},
+	}
+	for _, test := range tests {
+		b := EncodeSigned(test.value, test.size)
+		if !bytes.Equal(b, test.expected) {
+			t.Errorf("EncodeSigned(%d, %d) is %x, expected %x", test.value, test.size, b, test.expected)
+		}
+		if v := DecodeSigned(test.expected); test.value > -32768 && test.value < 32768 && v != test.value {
+			t.Errorf("DecodeSigned(%x) is %d, expected %d", test.expected, v, test.value)
+		}
+	}
+}
+
+func TestFixedPointRoundTrip(t *testing.T) {
+	b := EncodeFixedPoint(-16.216, OpticalLevelStep, 2)
+	if !bytes.Equal(b, []byte{0xe0, 0x54}) {
+		t.Errorf("-16.216 dBm is encoded as %x, expected e054", b)
+	}
+	if level := DecodeFixedPoint(b, OpticalLevelStep); level != -16.216 {
+		t.Errorf("%x is decoded as %.3f dBm, expected -16.216", b, level)
+	}
+
+	b = EncodeFixedPoint(-63.5, PowerThresholdStep, 1)
+	if !bytes.Equal(b, []byte{0x81}) {
+		t.Errorf("-63.5 dBm threshold is encoded as %x, expected 81", b)
+	}
+	if level := DecodeFixedPoint(b, PowerThresholdStep); level != -63.5 {
+		t.Errorf("%x is decoded as %.1f dBm, expected -63.5", b, level)
+	}
+}
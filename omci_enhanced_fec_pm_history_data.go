@@ -0,0 +1,54 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// Enhanced FEC PM History Data attribute numbers
+const (
+	EnhancedFecPmIntervalEndTime = iota + 1
+	EnhancedFecPmThresholdDataId
+	EnhancedFecPmCorrectedBytes
+	EnhancedFecPmCorrectedCodeWords
+	EnhancedFecPmUncorrectableCodeWords
+	EnhancedFecPmTotalCodeWords
+	EnhancedFecPmFecSeconds
+)
+
+// The Enhanced FEC PM History Data is the XG-PON and XGS-PON counterpart of the FEC PM History Data,
+// with 64-bit counters. Its Threshold Data id points to a Threshold Data 64-bit ME, which is not modeled:
+// its thresholds are set with SetPmThreshold.
+func init() {
+	MeDefinitions[EnhancedFecPMHistoryData] = &MeDefinition{
+		Name: "EnhancedFecPmHistoryData",
+		Attributes: []AttributeDefinition{
+			{Name: "IntervalEndTime", Size: 1, Access: AttrRead},
+			{Name: "ThresholdData64BitId", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "CorrectedBytes", Size: 8, Access: AttrRead},
+			{Name: "CorrectedCodeWords", Size: 8, Access: AttrRead},
+			{Name: "UncorrectableCodeWords", Size: 8, Access: AttrRead},
+			{Name: "TotalCodeWords", Size: 8, Access: AttrRead},
+			{Name: "FecSeconds", Size: 2, Access: AttrRead},
+		},
+		// Total code words has no TCA
+		Tcas: []TcaDefinition{
+			{Attribute: EnhancedFecPmCorrectedBytes, Alarm: 0, Threshold: 1},
+			{Attribute: EnhancedFecPmCorrectedCodeWords, Alarm: 1, Threshold: 2},
+			{Attribute: EnhancedFecPmUncorrectableCodeWords, Alarm: 2, Threshold: 3},
+			{Attribute: EnhancedFecPmFecSeconds, Alarm: 4, Threshold: 4},
+		},
+		Counters: []int{EnhancedFecPmTotalCodeWords},
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// Ethernet Frame Extended PM attribute numbers
+const (
+	EthernetExtPmIntervalEndTime = iota + 1
+	EthernetExtPmControlBlock
+	EthernetExtPmDropEvents
+	EthernetExtPmOctets
+	EthernetExtPmFrames
+	EthernetExtPmBroadcastFrames
+	EthernetExtPmMulticastFrames
+	EthernetExtPmCrcErroredFrames
+	EthernetExtPmUndersizeFrames
+	EthernetExtPmOversizeFrames
+	EthernetExtPmFrames64Octets
+	EthernetExtPmFrames65To127Octets
+	EthernetExtPmFrames128To255Octets
+	EthernetExtPmFrames256To511Octets
+	EthernetExtPmFrames512To1023Octets
+	EthernetExtPmFrames1024To1518Octets
+)
+
+// The Ethernet Frame Extended PM has 32-bit counters, the Ethernet Frame Extended PM 64-Bit is
+// the same ME with 64-bit counters. The control block starts with the Threshold Data id.
+func init() {
+	MeDefinitions[EthernetFrameExtendedPM] = ethernetFrameExtendedPmDefinition("EthernetFrameExtendedPm", 4)
+	MeDefinitions[EthernetFrameExtendedPM64Bit] = ethernetFrameExtendedPmDefinition("EthernetFrameExtendedPm64Bit", 8)
+}
+
+func ethernetFrameExtendedPmDefinition(name string, counterSize int) *MeDefinition {
+	attributes := []AttributeDefinition{
+		{Name: "IntervalEndTime", Size: 1, Access: AttrRead},
+		{Name: "ControlBlock", Size: 16, Access: AttrRead | AttrWrite | AttrSetByCreate},
+	}
+	for _, counter := range []string{"DropEvents", "Octets", "Frames", "BroadcastFrames", "MulticastFrames",
+		"CrcErroredFrames", "UndersizeFrames", "OversizeFrames", "Frames64Octets", "Frames65To127Octets",
+		"Frames128To255Octets", "Frames256To511Octets", "Frames512To1023Octets", "Frames1024To1518Octets"} {
+		attributes = append(attributes, AttributeDefinition{Name: counter, Size: counterSize, Access: AttrRead})
+	}
+
+	return &MeDefinition{
+		Name:       name,
+		Attributes: attributes,
+		// The first 2 bytes of the control block
+		ThresholdDataId: EthernetExtPmControlBlock,
+		// Only the error counters have a TCA
+		Tcas: []TcaDefinition{
+			{Attribute: EthernetExtPmDropEvents, Alarm: 0, Threshold: 1},
+			{Attribute: EthernetExtPmCrcErroredFrames, Alarm: 1, Threshold: 2},
+			{Attribute: EthernetExtPmUndersizeFrames, Alarm: 2, Threshold: 3},
+			{Attribute: EthernetExtPmOversizeFrames, Alarm: 3, Threshold: 4},
+		},
+		Counters: []int{
+			EthernetExtPmOctets,
+			EthernetExtPmFrames,
+			EthernetExtPmBroadcastFrames,
+			EthernetExtPmMulticastFrames,
+			EthernetExtPmFrames64Octets,
+			EthernetExtPmFrames65To127Octets,
+			EthernetExtPmFrames128To255Octets,
+			EthernetExtPmFrames256To511Octets,
+			EthernetExtPmFrames512To1023Octets,
+			EthernetExtPmFrames1024To1518Octets,
+		},
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MibExport is the MIB of an ONU: the attribute values, in hex and indexed by attribute number,
+// of the instances of each ME class in MeDefinitions but the Computed ones, which describe the simulator
+// rather than the ONU provisioning
+type MibExport map[OmciClass]map[uint16]map[int]string
+
+// ExportState returns the MIB of an ONU as indented JSON, with the classes, instances and attributes
+// sorted so that the exports of identical MIBs are identical
+func ExportState(oltId int, intfId uint32, onuId uint32) ([]byte, error) {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.RLock()
+	defer OnuOmciStateMapLock.RUnlock()
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+		return nil, errors.New(errmsg)
+	}
+
+	mib := MibExport{}
+	for class, instances := range state.mib {
+		def := MeDefinitions[class]
+		mib[class] = map[uint16]map[int]string{}
+		for instance, attrs := range instances {
+			values := map[int]string{}
+			for index, value := range attrs {
+				// The values are the ones read by the OLT
+				if index <= len(def.Attributes) && def.Attributes[index-1].Value != nil {
+					value = def.Attributes[index-1].Value(state, instance)
+				}
+				values[index] = hex.EncodeToString(value)
+			}
+			mib[class][instance] = values
+		}
+	}
+	return json.MarshalIndent(mib, "", "  ")
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write the golden files in testdata instead of comparing to them")
+
+// goldenIntfId is the interface of the ONUs compared to a golden file, it is below the ones of
+// newTestOnu so that the MIB doesn't depend on the order the tests run in
+const goldenIntfId uint32 = 999
+
+// matchGoldenState compares the export of the MIB of an ONU, see ExportState, to the one stored in
+// goldenPath and returns an error if they differ. With update the golden file is written instead.
+func matchGoldenState(oltId int, intfId uint32, onuId uint32, goldenPath string, update bool) error {
+	export, err := ExportState(oltId, intfId, onuId)
+	if err != nil {
+		return err
+	}
+	if update {
+		return ioutil.WriteFile(goldenPath, export, 0644)
+	}
+
+	golden, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(export, golden) {
+		return fmt.Errorf("MIB of ONU {intfid:%d, onuid:%d} differs from golden file %s", intfId, onuId, goldenPath)
+	}
+	return nil
+}
+
+// AssertMibMatchesGolden fails the test if the MIB of an ONU, as exported by ExportState, differs from
+// the golden file goldenPath. The golden file is written instead when the tests run with -update.
+func AssertMibMatchesGolden(t *testing.T, intfId uint32, onuId uint32, goldenPath string) {
+	t.Helper()
+	if err := matchGoldenState(0, intfId, onuId, goldenPath, *update); err != nil {
+		t.Errorf("%s, run the tests with -update if the change is expected", err)
+	}
+}
+
+func TestStandardServiceGolden(t *testing.T) {
+	onu := &testOnu{t: t, intfId: goldenIntfId, onuId: 1}
+	if result := onu.result(onu.send(MibReset, ONUData, 0, nil)); result != Success {
+		t.Fatalf("MibReset failed with result %d", result)
+	}
+	defer drainChannel()
+
+	// A bridged service on the first UNI: T-CONT, GEM port and its interworking TP, MAC bridge with
+	// its ANI and UNI side ports, and a VLAN tagging rule on the UNI
+	if result, _ := onu.set(TCONT, 0x8001, attributeMaskBit(TcontAllocId), 0x04, 0x00); result != Success {
+		t.Fatalf("Set of the T-CONT got result %d", result)
+	}
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+	onu.mustCreate(GalEthernetProfile, 0x0001, map[int][]byte{GalEthernetMaximumGemPayloadSize: {0x07, 0xd0}})
+	onu.mustCreate(MacBridgeServiceProfile, 0x0201, map[int][]byte{MacBridgeLearningInd: {0x01}})
+	onu.mustCreate(GEMInterworkingTP, 0x0401, map[int][]byte{
+		GemIwTpGemPortNetworkCtpPointer: {0x04, 0x01},
+		GemIwTpInterworkingOption:       {GemIwTpMacBridgedLan},
+		GemIwTpServiceProfilePointer:    {0x02, 0x01},
+		GemIwTpGalProfilePointer:        {0x00, 0x01},
+	})
+	onu.mustCreate(MacBridgePortConfigData, 0x2102, map[int][]byte{
+		MacBridgePortBridgeIdPointer: {0x02, 0x01},
+		MacBridgePortPortNum:         {0x02},
+		MacBridgePortTpType:          {0x05},
+		MacBridgePortTpPointer:       {0x04, 0x01},
+	})
+	onu.mustCreate(MacBridgePortConfigData, 0x0101, map[int][]byte{
+		MacBridgePortBridgeIdPointer: {0x02, 0x01},
+		MacBridgePortPortNum:         {0x01},
+		MacBridgePortTpType:          {0x01},
+		MacBridgePortTpPointer:       {0x01, 0x01},
+	})
+	onu.mustCreate(ExtendedVlanTagging, 0x0101, map[int][]byte{
+		ExtVlanAssociationType:     {2},
+		ExtVlanAssociatedMePointer: {0x01, 0x01},
+	})
+	// Untagged frames are tagged with VLAN 100
+	rule := []byte{0xf8, 0x00, 0x00, 0x00, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x03, 0x20}
+	if result, _ := onu.set(ExtendedVlanTagging, 0x0101, attributeMaskBit(ExtVlanReceivedFrameTable), rule...); result != Success {
+		t.Fatalf("Set of the VLAN tagging rule got result %d", result)
+	}
+
+	AssertMibMatchesGolden(t, onu.intfId, onu.onuId, "testdata/standard_service.golden.json")
+}
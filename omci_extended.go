@@ -0,0 +1,113 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The contents of an extended Get response are the result, the attribute mask, the optional-attribute
+// mask and the attribute execution mask, followed by the attribute values
+const extendedGetHeaderLength = 7
+
+// extendedGet answers an extended message set Get, serving all the requested attributes
+// which fit in an extended frame
+func extendedGet(ctx *HandlerContext) ([]byte, error) {
+	// The contents of the request start with their length
+	mask := binary.BigEndian.Uint16(ctx.Content[2:4])
+	limit := ExtendedMaxContentsLength - extendedGetHeaderLength
+
+	result := Success
+	var values []byte
+	var served, unsupported, failed uint16
+	if _, ok := MeDefinitions[ctx.Class]; ok {
+		unlock := lockForGet(ctx.Class, mask)
+		state := OnuOmciStateMap[ctx.Key]
+		if attrs, ok := state.getMe(ctx.Class, ctx.Instance); ok {
+			values, served, unsupported, failed = state.readMeAttributes(ctx.Class, ctx.Instance, attrs, mask, limit)
+		} else {
+			result = UnknownInstance
+		}
+		unlock()
+	} else if get := legacyGetter(ctx.Class, ctx.Instance, ctx.Key); get != nil {
+		values, served, unsupported, failed = legacyReadAttributes(mask, limit, get)
+	} else if !isClassSupported(ctx.Class) {
+		result = UnknownEntity
+	} else {
+		// Unlike the baseline Get, the size of the attributes of the unimplemented MEs is needed
+		unsupported = mask
+	}
+	if unsupported != 0 || failed != 0 {
+		result = AttributeFailure
+	}
+
+	ctx.Logger.WithFields(log.Fields{
+		"Result": result,
+		"Length": len(values),
+	}).Tracef("Omci extended Get")
+
+	contentsLength := extendedGetHeaderLength + len(values)
+	pkt := make([]byte, ExtendedHeaderLength+contentsLength+ExtendedMicLength)
+	binary.BigEndian.PutUint16(pkt[8:10], uint16(contentsLength))
+	pkt[10] = byte(result)
+	binary.BigEndian.PutUint16(pkt[11:13], served)
+	binary.BigEndian.PutUint16(pkt[13:15], unsupported)
+	binary.BigEndian.PutUint16(pkt[15:17], failed)
+	copy(pkt[ExtendedHeaderLength+extendedGetHeaderLength:], values)
+	return pkt, nil
+}
+
+// DecodeExtendedGetResponse returns the result and the attribute values, indexed by attribute number,
+// of an extended Get response for an ME class in MeDefinitions
+func DecodeExtendedGetResponse(class OmciClass, resp []byte) (OmciResult, map[int][]byte, error) {
+	def, ok := MeDefinitions[class]
+	if !ok {
+		return 0, nil, fmt.Errorf("Unknown ME class %d", class)
+	}
+	if len(resp) < ExtendedHeaderLength+extendedGetHeaderLength || resp[3] != ExtendedDeviceId {
+		return 0, nil, errors.New("Not an extended Get response")
+	}
+	contentsLength := int(binary.BigEndian.Uint16(resp[8:10]))
+	if contentsLength < extendedGetHeaderLength || len(resp) < ExtendedHeaderLength+contentsLength {
+		return 0, nil, fmt.Errorf("Truncated extended Get response: %d bytes of contents", contentsLength)
+	}
+
+	result := OmciResult(resp[ExtendedHeaderLength])
+	mask := binary.BigEndian.Uint16(resp[11:13])
+	r := NewContentReader(resp[ExtendedHeaderLength+extendedGetHeaderLength : ExtendedHeaderLength+contentsLength])
+	values := map[int][]byte{}
+	for index := 1; index <= len(def.Attributes); index++ {
+		if mask&attributeMaskBit(index) == 0 {
+			continue
+		}
+		// A Get reports the size of the table attributes
+		size := def.Attributes[index-1].Size
+		if def.Attributes[index-1].Table {
+			size = 4
+		}
+		value, err := r.ReadBytes(size)
+		if err != nil {
+			return result, nil, fmt.Errorf("Truncated value of attribute %d", index)
+		}
+		values[index] = value
+	}
+	return result, values, nil
+}
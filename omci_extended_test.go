@@ -0,0 +1,49 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+)
+
+func TestExtendedGet(t *testing.T) {
+	onu := newTestOnu(t)
+
+	// The 14 Circuit Pack attributes take 59 bytes, more than a baseline Get response carries
+	resp := onu.sendFrame(EncodeExtendedRequest(onu.nextTxId(), Get, CircuitPack, PonCircuitPack, []byte{0xff, 0xfc}))
+	result, values, err := DecodeExtendedGetResponse(CircuitPack, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != Success {
+		t.Fatalf("Extended Get got result %d, expected %d", result, Success)
+	}
+	if len(values) != 14 {
+		t.Errorf("Extended Get served %d attributes, expected 14", len(values))
+	}
+	if vendorId := values[CircuitPackVendorId]; string(vendorId) != "BRCM" {
+		t.Errorf("Vendor id is %q, expected BRCM", vendorId)
+	}
+	if len(resp) != ExtendedHeaderLength+extendedGetHeaderLength+59+ExtendedMicLength {
+		t.Errorf("Extended Get response is %d bytes long, expected %d", len(resp), ExtendedHeaderLength+extendedGetHeaderLength+59+ExtendedMicLength)
+	}
+
+	// A baseline Get of the same attributes can't serve them all
+	if result, _ := onu.get(CircuitPack, PonCircuitPack, 0xfffc); result != AttributeFailure {
+		t.Errorf("Baseline Get got result %d, expected %d", result, AttributeFailure)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+)
+
+// Extended VLAN Tagging Operation Config Data attribute numbers
+const (
+	ExtVlanAssociationType = iota + 1
+	ExtVlanReceivedFrameTableMaxSize
+	ExtVlanInputTpid
+	ExtVlanOutputTpid
+	ExtVlanDownstreamMode
+	ExtVlanReceivedFrameTable
+	ExtVlanAssociatedMePointer
+	ExtVlanDscpToPbitMapping
+)
+
+const (
+	extVlanTableEntrySize = 16
+	extVlanTableMaxSize   = 64
+)
+
+// extVlanAssociations are the ME classes an Extended VLAN Tagging Operation can be associated
+// with, indexed by association type
+var extVlanAssociations = map[uint8]OmciClass{
+	0:  47, // MAC Bridge Port Config Data
+	1:  Ieee8021pMapperServiceProfile,
+	2:  11, // PPTP Ethernet UNI
+	3:  IPHostConfigData,
+	4:  98,  // PPTP xDSL UNI
+	5:  266, // GEM Interworking TP
+	6:  281, // Multicast GEM Interworking TP
+	7:  162, // PPTP MoCA UNI
+	9:  286, // Ethernet Flow TP
+	10: 329, // Virtual Ethernet Interface Point
+	11: 333, // MPLS Pseudowire TP
+}
+
+func init() {
+	MeDefinitions[ExtendedVlanTagging] = &MeDefinition{
+		Name: "ExtendedVlanTaggingOperationConfigData",
+		Attributes: []AttributeDefinition{
+			{Name: "AssociationType", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "ReceivedFrameVlanTaggingOperationTableMaxSize", Size: 2, Access: AttrRead,
+				Default: []byte{0x00, extVlanTableMaxSize}},
+			{Name: "InputTpid", Size: 2, Access: AttrRead | AttrWrite, Default: []byte{0x81, 0x00}},
+			{Name: "OutputTpid", Size: 2, Access: AttrRead | AttrWrite, Default: []byte{0x81, 0x00}},
+			{Name: "DownstreamMode", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "ReceivedFrameVlanTaggingOperationTable", Size: extVlanTableEntrySize, Access: AttrRead | AttrWrite,
+				Table: true, SetEntry: setExtVlanTableEntry},
+			{Name: "AssociatedMePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "DscpToPbitMapping", Size: 24, Access: AttrRead | AttrWrite},
+		},
+		Validate: validateExtVlan,
+	}
+}
+
+// validateExtVlan checks the association type, and that the associated ME exists when it is
+// one of the MEs in MeDefinitions
+func validateExtVlan(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	class, ok := extVlanAssociations[attrs[ExtVlanAssociationType][0]]
+	if !ok {
+		return attributeMaskBit(ExtVlanAssociationType)
+	}
+	if _, ok := MeDefinitions[class]; ok {
+		if _, ok := state.getMe(class, attrs.uint16(ExtVlanAssociatedMePointer)); !ok {
+			return attributeMaskBit(ExtVlanAssociatedMePointer)
+		}
+	}
+	return 0
+}
+
+// setExtVlanTableEntry adds, replaces or removes a tagging rule, rules are identified by their
+// first 8 bytes (the filter) and removed by setting their last 8 bytes to 0xFF
+func setExtVlanTableEntry(table []byte, entry []byte) ([]byte, bool) {
+	filter := entry[:8]
+	remove := bytes.Equal(entry[8:], bytes.Repeat([]byte{0xFF}, 8))
+
+	for pos := 0; pos+extVlanTableEntrySize <= len(table); pos += extVlanTableEntrySize {
+		if !bytes.Equal(table[pos:pos+8], filter) {
+			continue
+		}
+		if remove {
+			return append(table[:pos], table[pos+extVlanTableEntrySize:]...), true
+		}
+		copy(table[pos:], entry)
+		return table, true
+	}
+
+	if remove {
+		return table, true
+	}
+	if len(table)/extVlanTableEntrySize >= extVlanTableMaxSize {
+		return table, false
+	}
+	return append(table, entry...), true
+}
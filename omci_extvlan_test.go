@@ -0,0 +1,90 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// newTestVeipOnu returns a freshly reset ONU with a single VEIP, instance 0x0501
+func newTestVeipOnu(t *testing.T) *testOnu {
+	defer func(numVeip int) { Config.NumVeip = numVeip }(Config.NumVeip)
+	Config.NumVeip = 1
+	return newTestOnu(t)
+}
+
+func TestExtVlanVeipAssociation(t *testing.T) {
+	onu := newTestVeipOnu(t)
+	onu.mustCreate(ExtendedVlanTagging, 0x0501, map[int][]byte{
+		ExtVlanAssociationType:     {10},
+		ExtVlanAssociatedMePointer: {0x05, 0x01},
+	})
+
+	mask := attributeMaskBit(ExtVlanAssociationType) | attributeMaskBit(ExtVlanAssociatedMePointer)
+	values := onu.mustGet(ExtendedVlanTagging, 0x0501, mask)
+	if values[0] != 10 || binary.BigEndian.Uint16(values[1:3]) != 0x0501 {
+		t.Errorf("Association type %d and pointer %x, expected 10 and 0501", values[0], values[1:3])
+	}
+}
+
+func TestExtVlanInvalidAssociation(t *testing.T) {
+	onu := newTestVeipOnu(t)
+
+	// Association type 8 is reserved
+	result := onu.create(ExtendedVlanTagging, 0x0501, map[int][]byte{
+		ExtVlanAssociationType:     {8},
+		ExtVlanAssociatedMePointer: {0x05, 0x01},
+	})
+	if result != ParameterError {
+		t.Errorf("Create with a reserved association type got result %d, expected %d", result, ParameterError)
+	}
+
+	// The VEIP association must point to a VEIP
+	result = onu.create(ExtendedVlanTagging, 0x0501, map[int][]byte{
+		ExtVlanAssociationType:     {10},
+		ExtVlanAssociatedMePointer: {0x05, 0x02},
+	})
+	if result != ParameterError {
+		t.Errorf("Create with a missing VEIP got result %d, expected %d", result, ParameterError)
+	}
+}
+
+func TestExtVlanBaselineTableSet(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.RejectBaselineTableSet = true
+	onu := newTestVeipOnu(t)
+	onu.mustCreate(ExtendedVlanTagging, 0x0501, map[int][]byte{
+		ExtVlanAssociationType:     {10},
+		ExtVlanAssociatedMePointer: {0x05, 0x01},
+	})
+
+	mask := attributeMaskBit(ExtVlanReceivedFrameTable)
+	content := append([]byte{byte(mask >> 8), byte(mask)}, make([]byte, extVlanTableEntrySize)...)
+	resp := onu.send(Set, ExtendedVlanTagging, 0x0501, content)
+	if result := onu.result(resp); result != NotSupported {
+		t.Errorf("Baseline Set of the operation table got result %d, expected %d", result, NotSupported)
+	}
+	if optional := binary.BigEndian.Uint16(resp[9:11]); optional != mask {
+		t.Errorf("Optional-attribute mask is %#04x, expected %#04x", optional, mask)
+	}
+
+	// The attributes which are not tables can still be set
+	if result, _ := onu.set(ExtendedVlanTagging, 0x0501, attributeMaskBit(ExtVlanDownstreamMode), 0x01); result != Success {
+		t.Errorf("Baseline Set of the downstream mode got result %d, expected %d", result, Success)
+	}
+}
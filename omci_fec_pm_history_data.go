@@ -0,0 +1,52 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// FEC PM History Data attribute numbers
+const (
+	FecPmIntervalEndTime = iota + 1
+	FecPmThresholdDataId
+	FecPmCorrectedBytes
+	FecPmCorrectedCodeWords
+	FecPmUncorrectableCodeWords
+	FecPmTotalCodeWords
+	FecPmFecSeconds
+)
+
+func init() {
+	MeDefinitions[FecPMHistoryData] = &MeDefinition{
+		Name: "FecPmHistoryData",
+		Attributes: []AttributeDefinition{
+			{Name: "IntervalEndTime", Size: 1, Access: AttrRead},
+			{Name: "ThresholdData12Id", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "CorrectedBytes", Size: 4, Access: AttrRead},
+			{Name: "CorrectedCodeWords", Size: 4, Access: AttrRead},
+			{Name: "UncorrectableCodeWords", Size: 4, Access: AttrRead},
+			{Name: "TotalCodeWords", Size: 4, Access: AttrRead},
+			{Name: "FecSeconds", Size: 2, Access: AttrRead},
+		},
+		ThresholdDataId: FecPmThresholdDataId,
+		// Total code words has no TCA
+		Tcas: []TcaDefinition{
+			{Attribute: FecPmCorrectedBytes, Alarm: 0, Threshold: 1},
+			{Attribute: FecPmCorrectedCodeWords, Alarm: 1, Threshold: 2},
+			{Attribute: FecPmUncorrectableCodeWords, Alarm: 2, Threshold: 3},
+			{Attribute: FecPmFecSeconds, Alarm: 4, Threshold: 4},
+		},
+		Counters: []int{FecPmTotalCodeWords},
+	}
+}
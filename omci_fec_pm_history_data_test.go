@@ -0,0 +1,59 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestFecPmGetLegacyOutput(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(FecPMHistoryData, 0x0101, map[int][]byte{FecPmThresholdDataId: {0x00, 0x00}})
+
+	// The response used to echo the upper byte of the requested mask, with zero values
+	resp := onu.send(Get, FecPMHistoryData, 0x0101, []byte{0x80, 0x00})
+	legacy := make([]byte, BaselineFrameLength-8)
+	legacy[1] = 0x80
+	if !bytes.Equal(resp[8:], legacy) {
+		t.Errorf("Got %x, expected %x", resp[8:], legacy)
+	}
+}
+
+func TestFecPmGetCorrectedBytes(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(FecPMHistoryData, 0x0101, map[int][]byte{FecPmThresholdDataId: {0x00, 0x00}})
+	if err := IncrementPmCounter(0, onu.intfId, onu.onuId, FecPMHistoryData, 0x0101, FecPmCorrectedBytes, 1234); err != nil {
+		t.Fatal(err)
+	}
+	if err := RolloverPmIntervals(0, onu.intfId, onu.onuId); err != nil {
+		t.Fatal(err)
+	}
+
+	mask := attributeMaskBit(FecPmIntervalEndTime) | attributeMaskBit(FecPmCorrectedBytes)
+	resp := onu.send(Get, FecPMHistoryData, 0x0101, []byte{byte(mask >> 8), byte(mask)})
+	if served := binary.BigEndian.Uint16(resp[9:11]); served != mask {
+		t.Errorf("Served mask is %#04x, expected %#04x", served, mask)
+	}
+	if resp[11] != 1 {
+		t.Errorf("Interval end time is %d, expected 1", resp[11])
+	}
+	if corrected := binary.BigEndian.Uint32(resp[12:16]); corrected != 1234 {
+		t.Errorf("Corrected bytes are %d, expected 1234", corrected)
+	}
+}
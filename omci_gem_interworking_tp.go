@@ -0,0 +1,93 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// GEM Interworking TP attribute numbers
+const (
+	GemIwTpGemPortNetworkCtpPointer = iota + 1
+	GemIwTpInterworkingOption
+	GemIwTpServiceProfilePointer
+	GemIwTpInterworkingTpPointer
+	GemIwTpPptpCounter
+	GemIwTpOperationalState
+	GemIwTpGalProfilePointer
+	GemIwTpGalLoopbackConfiguration
+)
+
+// GEM Interworking TP interworking options
+const (
+	GemIwTpCircuitEmulated   uint8 = 0
+	GemIwTpMacBridgedLan     uint8 = 1
+	GemIwTpIeee8021pMapper   uint8 = 5
+	GemIwTpDownstreamBcast   uint8 = 6
+	GemIwTpMplsPseudowireTdm uint8 = 7
+)
+
+// GAL Ethernet Profile attribute numbers
+const (
+	GalEthernetMaximumGemPayloadSize = iota + 1
+)
+
+func init() {
+	MeDefinitions[GEMInterworkingTP] = &MeDefinition{
+		Name: "GemInterworkingTerminationPoint",
+		Attributes: []AttributeDefinition{
+			{Name: "GemPortNetworkCtpConnectivityPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "InterworkingOption", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate,
+				Range: true, Max: uint64(GemIwTpMplsPseudowireTdm)},
+			{Name: "ServiceProfilePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "InterworkingTerminationPointPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PptpCounter", Size: 1, Access: AttrRead},
+			{Name: "OperationalState", Size: 1, Access: AttrRead, Value: gemIwTpOperationalState},
+			{Name: "GalProfilePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "GalLoopbackConfiguration", Size: 1, Access: AttrRead | AttrWrite, Range: true, Max: 1},
+		},
+	}
+	MeDefinitions[GalEthernetProfile] = &MeDefinition{
+		Name: "GalEthernetProfile",
+		Attributes: []AttributeDefinition{
+			{Name: "MaximumGemPayloadSize", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+	}
+}
+
+// gemIwTpServiceProfiles are the ME classes the service profile pointer of a GEM Interworking TP
+// points to, indexed by interworking option, for the ones in MeDefinitions
+var gemIwTpServiceProfiles = map[uint8]OmciClass{
+	GemIwTpMacBridgedLan: MacBridgeServiceProfile,
+}
+
+// gemIwTpOperationalState reports a GEM Interworking TP enabled (0) while the GEM Port Network CTP,
+// the GAL profile and the service profile it points to exist, disabled (1) otherwise. The service
+// profiles of the MEs not in MeDefinitions are taken for granted.
+func gemIwTpOperationalState(state *OnuOmciState, instance uint16) []byte {
+	attrs, _ := state.getMe(GEMInterworkingTP, instance)
+	disabled := []byte{0x01}
+
+	if _, ok := state.getMe(GEMPortNetworkCTP, attrs.uint16(GemIwTpGemPortNetworkCtpPointer)); !ok {
+		return disabled
+	}
+	if _, ok := state.getMe(GalEthernetProfile, attrs.uint16(GemIwTpGalProfilePointer)); !ok {
+		return disabled
+	}
+	if class, ok := gemIwTpServiceProfiles[attrs[GemIwTpInterworkingOption][0]]; ok {
+		if _, ok := state.getMe(class, attrs.uint16(GemIwTpServiceProfilePointer)); !ok {
+			return disabled
+		}
+	}
+	return []byte{0x00}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGemIwTpOperationalState(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+	onu.mustCreate(GalEthernetProfile, 0x0001, map[int][]byte{GalEthernetMaximumGemPayloadSize: {0x07, 0xd0}})
+	onu.mustCreate(MacBridgeServiceProfile, 0x0201, nil)
+	onu.mustCreate(GEMInterworkingTP, 0x0401, map[int][]byte{
+		GemIwTpGemPortNetworkCtpPointer: {0x04, 0x01},
+		GemIwTpInterworkingOption:       {GemIwTpMacBridgedLan},
+		GemIwTpServiceProfilePointer:    {0x02, 0x01},
+		GemIwTpGalProfilePointer:        {0x00, 0x01},
+	})
+
+	mask := attributeMaskBit(GemIwTpInterworkingOption) | attributeMaskBit(GemIwTpServiceProfilePointer) |
+		attributeMaskBit(GemIwTpOperationalState) | attributeMaskBit(GemIwTpGalProfilePointer)
+	expected := []byte{GemIwTpMacBridgedLan, 0x02, 0x01, 0x00, 0x00, 0x01}
+	if values := onu.mustGet(GEMInterworkingTP, 0x0401, mask)[:len(expected)]; !bytes.Equal(values, expected) {
+		t.Errorf("Interworking TP attributes are %x, expected %x", values, expected)
+	}
+
+	// The service profile is gone
+	if result := onu.result(onu.send(Delete, MacBridgeServiceProfile, 0x0201, nil)); result != Success {
+		t.Fatalf("Delete of the bridge got result %d", result)
+	}
+	operationalState := attributeMaskBit(GemIwTpOperationalState)
+	if state := onu.mustGet(GEMInterworkingTP, 0x0401, operationalState)[0]; state != 0x01 {
+		t.Errorf("Operational state is %d without the bridge, expected disabled", state)
+	}
+}
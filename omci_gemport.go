@@ -0,0 +1,139 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GEM Port Network CTP attribute numbers
+const (
+	GemPortCtpPortId = iota + 1
+	GemPortCtpTcontPointer
+	GemPortCtpDirection
+	GemPortCtpTrafficManagementPointerUpstream
+	GemPortCtpTrafficDescriptorPointerUpstream
+	GemPortCtpUniCounter
+	GemPortCtpPriorityQueuePointerDownstream
+	GemPortCtpEncryptionState
+	GemPortCtpTrafficDescriptorPointerDownstream
+	GemPortCtpEncryptionKeyRing
+)
+
+func init() {
+	MeDefinitions[GEMPortNetworkCTP] = &MeDefinition{
+		Name: "GemPortNetworkCtp",
+		Attributes: []AttributeDefinition{
+			{Name: "PortId", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "TcontPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "Direction", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "TrafficManagementPointerUpstream", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "TrafficDescriptorProfilePointerUpstream", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "UniCounter", Size: 1, Access: AttrRead},
+			{Name: "PriorityQueuePointerDownstream", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "EncryptionState", Size: 1, Access: AttrRead, Value: gemPortEncryptionState},
+			{Name: "TrafficDescriptorProfilePointerDownstream", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "EncryptionKeyRing", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+		Validate: validateGemPortNetworkCtp,
+	}
+}
+
+// gemPortEncryptionState reports AES-128 encryption (1) for the GEM ports with a key ring, none (0) otherwise
+func gemPortEncryptionState(state *OnuOmciState, instance uint16) []byte {
+	if state.mib[GEMPortNetworkCTP][instance][GemPortCtpEncryptionKeyRing][0] != 0 {
+		return []byte{0x01}
+	}
+	return []byte{0x00}
+}
+
+func validateGemPortNetworkCtp(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	var failed uint16
+
+	pq := attrs.uint16(GemPortCtpPriorityQueuePointerDownstream)
+	if !isNullPointer(pq) && !state.priorityQueueExists(pq) {
+		failed |= attributeMaskBit(GemPortCtpPriorityQueuePointerDownstream)
+	}
+
+	for _, index := range []int{GemPortCtpTrafficDescriptorPointerUpstream, GemPortCtpTrafficDescriptorPointerDownstream} {
+		td := attrs.uint16(index)
+		if _, ok := state.getMe(TrafficDescriptor, td); !isNullPointer(td) && !ok {
+			failed |= attributeMaskBit(index)
+		}
+	}
+
+	// No encryption, unicast, broadcast or downstream unicast key ring
+	if attrs[GemPortCtpEncryptionKeyRing][0] > 3 {
+		failed |= attributeMaskBit(GemPortCtpEncryptionKeyRing)
+	}
+
+	if Config.ValidateGemPortDirection {
+		failed |= validateGemPortDirection(attrs)
+	}
+
+	return failed
+}
+
+// GEM Port Network CTP directions
+const (
+	GemPortUpstream      uint8 = 1 // UNI-to-ANI
+	GemPortDownstream    uint8 = 2 // ANI-to-UNI
+	GemPortBidirectional uint8 = 3
+)
+
+// validateGemPortDirection checks that a GEM port only points to the T-CONT and the downstream
+// priority queue of the directions it carries traffic in
+func validateGemPortDirection(attrs MeAttributes) uint16 {
+	var failed uint16
+
+	direction := attrs[GemPortCtpDirection][0]
+	switch direction {
+	case GemPortUpstream, GemPortDownstream, GemPortBidirectional:
+	default:
+		return attributeMaskBit(GemPortCtpDirection)
+	}
+
+	if direction == GemPortDownstream && !isNullPointer(attrs.uint16(GemPortCtpTcontPointer)) {
+		failed |= attributeMaskBit(GemPortCtpTcontPointer)
+	}
+	if direction == GemPortUpstream && !isNullPointer(attrs.uint16(GemPortCtpPriorityQueuePointerDownstream)) {
+		failed |= attributeMaskBit(GemPortCtpPriorityQueuePointerDownstream)
+	}
+
+	return failed
+}
+
+// priorityQueueExists reports whether a Priority Queue is one of those reported in the MIB upload,
+// NumPriorQPerTcont for each of the NumTcont T-CONTs in each direction. Downstream queues are numbered
+// from 0x0001 and upstream ones from 0x8001
+func (s *OnuOmciState) priorityQueueExists(instance uint16) bool {
+	id := instance & 0x7FFF
+	return id >= 1 && id <= NumTcont*NumPriorQPerTcont
+}
+
+// GetGemPortsInUse returns the number of GEM Port Network CTPs the OLT created on an ONU
+func GetGemPortsInUse(oltId int, intfId uint32, onuId uint32) (int, error) {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.RLock()
+	defer OnuOmciStateMapLock.RUnlock()
+	if state, ok := OnuOmciStateMap[key]; ok {
+		return len(state.mib[GEMPortNetworkCTP]), nil
+	}
+	errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+	return 0, errors.New(errmsg)
+}
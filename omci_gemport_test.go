@@ -0,0 +1,206 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// gemPortAttributes are the attributes of a bidirectional GEM port on the first T-CONT
+func gemPortAttributes(portId uint16) map[int][]byte {
+	return map[int][]byte{
+		GemPortCtpPortId:                             {byte(portId >> 8), byte(portId)},
+		GemPortCtpTcontPointer:                       {0x80, 0x01},
+		GemPortCtpDirection:                          {GemPortBidirectional},
+		GemPortCtpTrafficManagementPointerUpstream:   {0x80, 0x01},
+		GemPortCtpTrafficDescriptorPointerUpstream:   {0xff, 0xff},
+		GemPortCtpPriorityQueuePointerDownstream:     {0xff, 0xff},
+		GemPortCtpTrafficDescriptorPointerDownstream: {0xff, 0xff},
+	}
+}
+
+func TestGemPortPriorityQueuePointer(t *testing.T) {
+	onu := newTestOnu(t)
+
+	// The priority queues exist before the OLT walks the MIB upload
+	attrs := gemPortAttributes(0x0401)
+	attrs[GemPortCtpPriorityQueuePointerDownstream] = []byte{0x00, 0x08}
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, attrs)
+	values := onu.mustGet(GEMPortNetworkCTP, 0x0401, attributeMaskBit(GemPortCtpPriorityQueuePointerDownstream))
+	if pq := binary.BigEndian.Uint16(values); pq != 0x0008 {
+		t.Errorf("Priority queue pointer is %#04x, expected 0x0008", pq)
+	}
+
+	result, _ := onu.set(GEMPortNetworkCTP, 0x0401, attributeMaskBit(GemPortCtpPriorityQueuePointerDownstream), 0x00, 0x40)
+	if result != Success {
+		t.Errorf("Set of the last priority queue got result %d, expected %d", result, Success)
+	}
+	values = onu.mustGet(GEMPortNetworkCTP, 0x0401, attributeMaskBit(GemPortCtpPriorityQueuePointerDownstream))
+	if pq := binary.BigEndian.Uint16(values); pq != 0x0040 {
+		t.Errorf("Priority queue pointer is %#04x, expected 0x0040", pq)
+	}
+}
+
+func TestGemPortUnknownPriorityQueue(t *testing.T) {
+	onu := newTestOnu(t)
+
+	attrs := gemPortAttributes(0x0401)
+	attrs[GemPortCtpPriorityQueuePointerDownstream] = []byte{0x00, NumTcont*NumPriorQPerTcont + 1}
+	if result := onu.create(GEMPortNetworkCTP, 0x0401, attrs); result != ParameterError {
+		t.Errorf("Create got result %d, expected %d", result, ParameterError)
+	}
+}
+
+func TestGemPortDirection(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.ValidateGemPortDirection = true
+	onu := newTestOnu(t)
+
+	// A downstream-only GEM port has no T-CONT
+	attrs := gemPortAttributes(0x0401)
+	attrs[GemPortCtpDirection] = []byte{GemPortDownstream}
+	if result := onu.create(GEMPortNetworkCTP, 0x0401, attrs); result != ParameterError {
+		t.Errorf("Create got result %d, expected %d", result, ParameterError)
+	}
+
+	attrs[GemPortCtpTcontPointer] = []byte{0xff, 0xff}
+	attrs[GemPortCtpTrafficManagementPointerUpstream] = []byte{0xff, 0xff}
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, attrs)
+
+	// Turning it into an upstream port contradicts its downstream priority queue
+	mask := attributeMaskBit(GemPortCtpPriorityQueuePointerDownstream)
+	if result, _ := onu.set(GEMPortNetworkCTP, 0x0401, mask, 0x00, 0x01); result != Success {
+		t.Fatalf("Set of the priority queue got result %d", result)
+	}
+	if result, _ := onu.set(GEMPortNetworkCTP, 0x0401, attributeMaskBit(GemPortCtpDirection), GemPortUpstream); result != ParameterError {
+		t.Errorf("Set of the direction got result %d, expected %d", result, ParameterError)
+	}
+}
+
+func TestGemPortEncryptionKeyRing(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+
+	// Broadcast key ring
+	mask := attributeMaskBit(GemPortCtpEncryptionKeyRing)
+	if result, _ := onu.set(GEMPortNetworkCTP, 0x0401, mask, 0x02); result != Success {
+		t.Fatalf("Set of the key ring got result %d", result)
+	}
+	values := onu.mustGet(GEMPortNetworkCTP, 0x0401, mask|attributeMaskBit(GemPortCtpEncryptionState))
+	if values[0] != 0x01 || values[1] != 0x02 {
+		t.Errorf("Encryption state and key ring are %x, expected 0102", values[:2])
+	}
+
+	if result, _ := onu.set(GEMPortNetworkCTP, 0x0401, mask, 0x04); result != ParameterError {
+		t.Errorf("Set of key ring 4 got result %d, expected %d", result, ParameterError)
+	}
+	if values := onu.mustGet(GEMPortNetworkCTP, 0x0401, mask); values[0] != 0x02 {
+		t.Errorf("Key ring is %d after a rejected Set, expected 2", values[0])
+	}
+
+	attrs := gemPortAttributes(0x0402)
+	attrs[GemPortCtpEncryptionKeyRing] = []byte{0xff}
+	if result := onu.create(GEMPortNetworkCTP, 0x0402, attrs); result != ParameterError {
+		t.Errorf("Create with key ring 255 got result %d, expected %d", result, ParameterError)
+	}
+}
+
+func TestGemPortFullGet(t *testing.T) {
+	onu := newTestOnu(t)
+	attrs := gemPortAttributes(0x0401)
+	attrs[GemPortCtpEncryptionKeyRing] = []byte{0x01}
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, attrs)
+
+	// All the 10 attributes, 16 bytes
+	values := onu.mustGet(GEMPortNetworkCTP, 0x0401, 0xffc0)
+	expected := []byte{
+		0x04, 0x01, // Port-ID
+		0x80, 0x01, // T-CONT pointer
+		GemPortBidirectional,
+		0x80, 0x01, // Traffic management pointer upstream
+		0xff, 0xff, // Traffic descriptor profile pointer upstream
+		0x00,       // UNI counter
+		0xff, 0xff, // Priority queue pointer downstream
+		0x01,       // Encryption state
+		0xff, 0xff, // Traffic descriptor profile pointer downstream
+		0x01, // Encryption key ring
+	}
+	if !bytes.Equal(values[:len(expected)], expected) {
+		t.Errorf("GEM port attributes are %x, expected %x", values[:len(expected)], expected)
+	}
+}
+
+func TestGemPortWildcardGet(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.AllowWildcardInstance = true
+	onu := newTestOnu(t)
+	mask := attributeMaskBit(GemPortCtpPortId)
+
+	if result, _ := onu.get(GEMPortNetworkCTP, WildcardInstance, mask); result != UnknownInstance {
+		t.Errorf("Wildcard Get without GEM ports got result %d, expected %d", result, UnknownInstance)
+	}
+
+	// The wildcard reads the instance with the lowest id
+	onu.mustCreate(GEMPortNetworkCTP, 0x0402, gemPortAttributes(0x0402))
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+	resp := onu.send(Get, GEMPortNetworkCTP, WildcardInstance, []byte{byte(mask >> 8), byte(mask)})
+	if portId := binary.BigEndian.Uint16(resp[11:]); portId != 0x0401 {
+		t.Errorf("Wildcard Get returned Port-ID %#04x, expected 0x0401", portId)
+	}
+	if instance := binary.BigEndian.Uint16(resp[6:8]); instance != 0x0401 {
+		t.Errorf("Wildcard Get answered for instance %#04x, expected 0x0401", instance)
+	}
+
+	Config.AllowWildcardInstance = false
+	resp = onu.send(Get, GEMPortNetworkCTP, WildcardInstance, []byte{byte(mask >> 8), byte(mask)})
+	if result := onu.result(resp); result != UnknownInstance {
+		t.Errorf("Wildcard Get not allowed got result %d, expected %d", result, UnknownInstance)
+	}
+	if instance := binary.BigEndian.Uint16(resp[6:8]); instance != WildcardInstance {
+		t.Errorf("Wildcard Get not allowed answered for instance %#04x, expected %#04x", instance, WildcardInstance)
+	}
+}
+
+func TestGemPortDefaultProvider(t *testing.T) {
+	onu := newTestOnu(t)
+	RegisterDefaultProvider(GEMPortNetworkCTP, func(oltId int, intfId uint32, onuId uint32, instance uint16) map[int][]byte {
+		if intfId != onu.intfId {
+			return nil
+		}
+		return map[int][]byte{
+			GemPortCtpDirection:  {GemPortDownstream},
+			GemPortCtpUniCounter: {byte(instance)},
+		}
+	})
+	defer RegisterDefaultProvider(GEMPortNetworkCTP, nil)
+
+	// The direction is set by the Create, the provider replaces the default of the UNI counter
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+	mask := attributeMaskBit(GemPortCtpDirection) | attributeMaskBit(GemPortCtpUniCounter)
+	if values := onu.mustGet(GEMPortNetworkCTP, 0x0401, mask); values[0] != GemPortBidirectional || values[1] != 0x01 {
+		t.Errorf("Direction and UNI counter are %d and %d, expected %d and 1", values[0], values[1], GemPortBidirectional)
+	}
+
+	// A nil provider restores the built-in defaults
+	RegisterDefaultProvider(GEMPortNetworkCTP, nil)
+	onu.mustCreate(GEMPortNetworkCTP, 0x0402, gemPortAttributes(0x0402))
+	if values := onu.mustGet(GEMPortNetworkCTP, 0x0402, mask); values[1] != 0x00 {
+		t.Errorf("UNI counter is %d without a provider, expected 0", values[1])
+	}
+}
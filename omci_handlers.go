@@ -17,13 +17,13 @@
 package core
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 )
 
-type OmciMsgHandler func(class OmciClass, content OmciContent, key OnuKey) ([]byte, error)
+// OmciMsgHandler answers the requests of a message type, it logs with the logger of the request
+type OmciMsgHandler func(ctx *HandlerContext) ([]byte, error)
 
 var Handlers = map[OmciMsgType]OmciMsgHandler{
 	MibReset:         mibReset,
@@ -32,30 +32,26 @@ var Handlers = map[OmciMsgType]OmciMsgHandler{
 	Set:              set,
 	Create:           create,
 	Get:              get,
+	GetNext:          getNext,
+	GetCurrentData:   getCurrentData,
 	GetAllAlarms:     getAllAlarms,
 	GetAllAlarmsNext: getAllAlarmsNext,
 	SynchronizeTime:  syncTime,
-	Delete:           delete,
+	Delete:           deleteHandler,
 	Reboot:           reboot,
 	Test: testHandler,
 }
 
-func mibReset(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func mibReset(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci MibReset")
-	OnuOmciStateMapLock.RLock()
-	if state, ok := OnuOmciStateMap[key]; ok {
-		log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Reseting OnuOmciState")
+	ctx.Logger.Tracef("Omci MibReset")
+	OnuOmciStateMapLock.Lock()
+	if state, ok := OnuOmciStateMap[ctx.Key]; ok {
+		ctx.Logger.Tracef("Reseting OnuOmciState")
 		state.ResetOnuOmciState()
 	}
-	OnuOmciStateMapLock.RUnlock()
+	OnuOmciStateMapLock.Unlock()
 
 	pkt = []byte{
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00,
@@ -67,13 +63,21 @@ func mibReset(class OmciClass, content OmciContent, key OnuKey) ([]byte, error)
 	return pkt, nil
 }
 
-func mibUpload(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func mibUpload(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci MibUpload")
+	ctx.Logger.Tracef("Omci MibUpload")
+
+	// A new upload starts from the first ME, a MibReset aborts it
+	numMibUploads := numStaticMibUploads
+	OnuOmciStateMapLock.Lock()
+	if state, ok := OnuOmciStateMap[ctx.Key]; ok {
+		state.resetMibUpload()
+		state.mibUploadActive = true
+		state.uploadedMes = state.mibUploadMes()
+		numMibUploads += len(state.uploadedMes)
+	}
+	OnuOmciStateMapLock.Unlock()
 
 	pkt = []byte{
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00,
@@ -83,25 +87,30 @@ func mibUpload(class OmciClass, content OmciContent, key OnuKey) ([]byte, error)
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 
-	pkt[8] = NumMibUploadsHigherByte
-	pkt[9] = NumMibUploadsLowerByte
+	pkt[8] = byte(numMibUploads >> 8)
+	pkt[9] = byte(numMibUploads & 0xFF)
 
 	return pkt, nil
 }
 
-func mibUploadNext(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func mibUploadNext(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
-	OnuOmciStateMapLock.RLock()
-	state := OnuOmciStateMap[key]
-	OnuOmciStateMapLock.RUnlock()
+	// The MIB upload cursor of the ONU is moved by every MibUploadNext
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	state := OnuOmciStateMap[ctx.Key]
 	// commandNumber is the "Command number" attribute received in "MIB Upload Next" OMCI message
-	commandNumber := (uint16(content[1])) | (uint16(content[0])<<8)
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
+	commandNumber := (uint16(ctx.Content[1])) | (uint16(ctx.Content[0])<<8)
+	ctx.Logger.WithFields(log.Fields{
 		"CommandNumber": commandNumber,
 	}).Tracef("Omci MibUploadNext")
 
+	if !state.mibUploadActive {
+		state.setMibResetRequired()
+		errstr := fmt.Sprintf("%v - MibUploadNext %d without an active MibUpload", ctx.Key, commandNumber)
+		return nil, errors.New(errstr)
+	}
+
 	switch commandNumber {
 	case 0:
 		// ONT Data (2)
@@ -113,6 +122,7 @@ func mibUploadNext(class OmciClass, content OmciContent, key OnuKey) ([]byte, er
 			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+		pkt[14] = state.mibDataSync()
 	case 1:
 		// Circuit Pack (6) - #1
 		// log.Println("Circuit Pack")
@@ -377,17 +387,42 @@ func mibUploadNext(class OmciClass, content OmciContent, key OnuKey) ([]byte, er
 			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		}
 
+	case 291:
+		// Port Mapping Package (297), the port lists beyond the first one are empty
+		pkt = state.mibUploadEntry(PortMappingPackage, 0, attributeMaskBit(PortMappingMaxPorts)|attributeMaskBit(PortMappingPortList1))
+
 	default:
+		// The MEs depending on the ONU configuration follow the static ones
+		if index := int(commandNumber) - numStaticMibUploads; index >= 0 && index < len(state.uploadedMes) {
+			me := state.uploadedMes[index]
+			pkt = state.mibUploadEntry(me.Class, me.Instance, me.mask)
+			break
+		}
 		state.extraMibUploadCtr++
-		errstr := fmt.Sprintf("%v - Invalid MibUpload request: %d, extras: %d", key, state.mibUploadCtr, state)
+		state.setMibResetRequired()
+		errstr := fmt.Sprintf("%v - Invalid MibUpload request: %d, extras: %d", ctx.Key, state.mibUploadCtr, state.extraMibUploadCtr)
 		return nil, errors.New(errstr)
 	}
 
 	state.mibUploadCtr++
+
+	if Config.CheckMibUpload {
+		if err := state.checkMibUploadEntry(pkt); err != nil {
+			return nil, fmt.Errorf("%v - MibUploadNext %d: %v", ctx.Key, commandNumber, err)
+		}
+	}
+
+	if state.corruptEntries[commandNumber] {
+		delete(state.corruptEntries, commandNumber)
+		ctx.Logger.WithFields(log.Fields{
+			"CommandNumber": commandNumber,
+		}).Warnf("Sending a corrupt MibUploadNext response")
+		pkt = pkt[:corruptUploadEntryLength]
+	}
 	return pkt, nil
 }
 
-func set(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func set(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 
 	pkt = []byte{
@@ -398,61 +433,87 @@ func set(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci Set")
+	if _, ok := MeDefinitions[ctx.Class]; ok {
+		OnuOmciStateMapLock.Lock()
+		result, failed := OnuOmciStateMap[ctx.Key].setMe(ctx.Class, ctx.Instance, ctx.Content)
+		OnuOmciStateMapLock.Unlock()
+		pkt[8] = byte(result)
+		pkt[11] = uint8(failed >> 8) // Attribute execution mask
+		pkt[12] = uint8(failed & 0xFF)
+	} else if ctx.Class == ONUG {
+		OnuOmciStateMapLock.Lock()
+		result, failed := OnuOmciStateMap[ctx.Key].setOnuGAttributes(ctx.Content)
+		OnuOmciStateMapLock.Unlock()
+		pkt[8] = byte(result)
+		pkt[11] = uint8(failed >> 8)
+		pkt[12] = uint8(failed & 0xFF)
+	}
+
+	ctx.Logger.Tracef("Omci Set")
 
 	return pkt, nil
 }
 
-func create(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func create(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 
-	if class == GEMPortNetworkCTP {
-		OnuOmciStateMapLock.RLock()
-		defer OnuOmciStateMapLock.RUnlock()
-		if onuOmciState, ok := OnuOmciStateMap[key]; !ok {
-			log.WithFields(log.Fields{
-				"IntfId": key.IntfId,
-				"OnuId": key.OnuId,
-			}).Tracef("ONU Key Error")
+	pkt = []byte{
+		0x00, 0x00, 0x00, 0x00, 0x01, 0x10, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	if _, ok := MeDefinitions[ctx.Class]; ok {
+		OnuOmciStateMapLock.Lock()
+		result, failed := OnuOmciStateMap[ctx.Key].createMe(ctx.Class, ctx.Instance, ctx.Content)
+		OnuOmciStateMapLock.Unlock()
+		pkt[8] = byte(result)
+		pkt[9] = uint8(failed >> 8) // Attribute execution mask
+		pkt[10] = uint8(failed & 0xFF)
+		if result != Success {
+			ctx.Logger.WithFields(log.Fields{
+				"class": ctx.Class,
+				"instance": ctx.Instance,
+				"result": result,
+			}).Warnf("Omci Create failed")
+			return pkt, nil
+		}
+	}
+
+	if ctx.Class == GEMPortNetworkCTP {
+		OnuOmciStateMapLock.Lock()
+		defer unlockAndNotify()
+		if onuOmciState, ok := OnuOmciStateMap[ctx.Key]; !ok {
+			ctx.Logger.Tracef("ONU Key Error")
 			return nil, errors.New("ONU Key Error")
 		} else {
-			onuOmciState.gemPortId = binary.BigEndian.Uint16(content[:2])
-			log.WithFields(log.Fields{
-				"IntfId": key.IntfId,
-				"OnuId": key.OnuId,
-			}).Tracef("Gem Port Id %d", key, onuOmciState)
+			// The Port-ID is the first set-by-create attribute
+			gemPortId, err := NewContentReader(ctx.Content[:]).ReadUint16()
+			if err != nil {
+				return nil, err
+			}
+			onuOmciState.gemPortId = gemPortId
+			ctx.Logger.Tracef("Gem Port Id %d", onuOmciState.gemPortId)
 			// FIXME
-			OnuOmciStateMap[key].state = DONE
-			omciCh <- OmciChMessage{
+			OnuOmciStateMap[ctx.Key].state = DONE
+			queueNotification(OmciChMessage{
 				Type: GemPortAdded,
 				Data: OmciChMessageData{
-					OnuId: key.OnuId,
-					IntfId: key.IntfId,
+					OnuId: ctx.Key.OnuId,
+					IntfId: ctx.Key.IntfId,
 				},
-			}
+			})
 		}
 	}
 
-	pkt = []byte{
-		0x00, 0x00, 0x00, 0x00, 0x01, 0x10, 0x00, 0x01,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
-
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci Create")
+	ctx.Logger.Tracef("Omci Create")
 
 	return pkt, nil
 }
 
-func get(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func get(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 
 	pkt = []byte{
@@ -463,16 +524,51 @@ func get(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 
-	pkt = GetAttributes(class, content, key, pkt)
+	pkt[6] = byte(ctx.Instance >> 8)
+	pkt[7] = byte(ctx.Instance & 0xFF)
+	pkt = GetAttributes(ctx.Class, ctx.Instance, ctx.Content, ctx.Key, pkt)
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci Get")
+	ctx.Logger.Tracef("Omci Get")
 	return pkt, nil
 }
 
-func getAllAlarms(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func getNext(ctx *HandlerContext) ([]byte, error) {
+	pkt := make([]byte, BaselineFrameLength)
+
+	// Only the tables of the MEs in MeDefinitions can be read
+	if _, ok := MeDefinitions[ctx.Class]; ok {
+		OnuOmciStateMapLock.RLock()
+		pkt[8] = byte(OnuOmciStateMap[ctx.Key].getNextMeAttribute(ctx.Class, ctx.Instance, ctx.Content, pkt))
+		OnuOmciStateMapLock.RUnlock()
+	} else if !isClassSupported(ctx.Class) {
+		pkt[8] = byte(UnknownEntity)
+	} else {
+		pkt[8] = byte(ParameterError)
+	}
+
+	ctx.Logger.Tracef("Omci GetNext")
+	return pkt, nil
+}
+
+func getCurrentData(ctx *HandlerContext) ([]byte, error) {
+	pkt := make([]byte, BaselineFrameLength)
+
+	// Only the PM MEs in MeDefinitions have a current interval
+	if def, ok := MeDefinitions[ctx.Class]; ok && len(def.Tcas) != 0 {
+		OnuOmciStateMapLock.Lock()
+		pkt[8] = byte(OnuOmciStateMap[ctx.Key].getCurrentMeAttributes(ctx.Class, ctx.Instance, uint16(getAttributeMask(ctx.Content)), pkt))
+		OnuOmciStateMapLock.Unlock()
+	} else if !isClassSupported(ctx.Class) {
+		pkt[8] = byte(UnknownEntity)
+	} else {
+		pkt[8] = byte(NotSupported)
+	}
+
+	ctx.Logger.Tracef("Omci GetCurrentData")
+	return pkt, nil
+}
+
+func getAllAlarms(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 
 	// Report number of commands as 1, basically there is always one alarm to get, the ONU/PPTP locked, link down or up
@@ -484,15 +580,12 @@ func getAllAlarms(class OmciClass, content OmciContent, key OnuKey) ([]byte, err
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci GetAllAlarms")
+	ctx.Logger.Tracef("Omci GetAllAlarms")
 
 	return pkt, nil
 }
 
-func syncTime(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func syncTime(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 
 	pkt = []byte{
@@ -503,19 +596,16 @@ func syncTime(class OmciClass, content OmciContent, key OnuKey) ([]byte, error)
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci syncTime")
+	ctx.Logger.Tracef("Omci syncTime")
 
 	return pkt, nil
 }
 
-func getAllAlarmsNext(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func getAllAlarmsNext(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 
 	OnuOmciStateMapLock.Lock()
-	if OnuOmciState, ok := OnuOmciStateMap[key]; ok {
+	if OnuOmciState, ok := OnuOmciStateMap[ctx.Key]; ok {
 		// if we are locked then admin down was sent and PPTP 257 is in alarm/locked state, this ensures get alarm
 		// shows that
 		if OnuOmciState.state == LOCKED {
@@ -540,15 +630,12 @@ func getAllAlarmsNext(class OmciClass, content OmciContent, key OnuKey) ([]byte,
 	}
 	OnuOmciStateMapLock.Unlock()
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci GetAllAlarmsNext")
+	ctx.Logger.Tracef("Omci GetAllAlarmsNext")
 
 	return pkt, nil
 }
 
-func delete(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func deleteHandler(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 
 	pkt = []byte{
@@ -559,15 +646,18 @@ func delete(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci Delete")
+	if _, ok := MeDefinitions[ctx.Class]; ok {
+		OnuOmciStateMapLock.Lock()
+		pkt[8] = byte(OnuOmciStateMap[ctx.Key].deleteMe(ctx.Class, ctx.Instance))
+		OnuOmciStateMapLock.Unlock()
+	}
+
+	ctx.Logger.Tracef("Omci Delete")
 
 	return pkt, nil
 }
 
-func reboot(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func reboot(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 	pkt = []byte{
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
@@ -577,18 +667,15 @@ func reboot(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Tracef("Omci Reboot")
+	ctx.Logger.Tracef("Omci Reboot")
 	return pkt, nil
 }
 
-func testHandler(class OmciClass, content OmciContent, key OnuKey) ([]byte, error) {
+func testHandler(ctx *HandlerContext) ([]byte, error) {
 	var pkt []byte
 	pkt = []byte{
 		0x20, 0x52, 0x45, 0x43, 0x56, 0x00, 0x20, 0x53,
-		0x45, 0x4e, 0x44, 0x00, 0x88, 0xb5, 0x02, 0x3f,
+		0x00, 0x4e, 0x44, 0x00, 0x88, 0xb5, 0x02, 0x3f,
 		0x1b, 0x0a, 0x01, 0x07, 0x80, 0x01, 0x01, 0x00,
 		0xa5, 0x03, 0xe0, 0x0b, 0x05, 0x0b, 0x2b, 0x09,
 		0x1c, 0xd0, 0x0c, 0x32, 0x80, 0x00, 0x00, 0x00,
@@ -596,10 +683,16 @@ func testHandler(class OmciClass, content OmciContent, key OnuKey) ([]byte, erro
 		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		0x00, 0x28, 0xf8, 0x13, 0x1b, 0x36,}
 
-	log.WithFields(log.Fields{
-		"IntfId": key.IntfId,
-		"OnuId": key.OnuId,
-	}).Trace("Omci Test")
+	// The power feed voltage, laser bias current and temperature of the ANI-G self test results are
+	// the telemetry of the PON circuit pack
+	OnuOmciStateMapLock.RLock()
+	telemetry := OnuOmciStateMap[ctx.Key].circuitPackTelemetry(PonCircuitPack)
+	OnuOmciStateMapLock.RUnlock()
+	copy(pkt[23:25], EncodeFixedPoint(telemetry.SupplyVoltage, SupplyVoltageStep, 2))
+	copy(pkt[32:34], EncodeFixedPoint(telemetry.LaserBias, LaserBiasStep, 2))
+	copy(pkt[35:37], EncodeFixedPoint(telemetry.Temperature, TemperatureStep, 2))
+
+	ctx.Logger.Trace("Omci Test")
 	return pkt, nil
 }
 
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// IP Host Config Data attribute numbers
+const (
+	IpHostIpOptions = iota + 1
+	IpHostMacAddress
+	IpHostOnuIdentifier
+	IpHostIpAddress
+	IpHostMask
+	IpHostGateway
+	IpHostPrimaryDns
+	IpHostSecondaryDns
+	IpHostCurrentAddress
+	IpHostCurrentMask
+	IpHostCurrentGateway
+	IpHostCurrentPrimaryDns
+	IpHostCurrentSecondaryDns
+	IpHostDomainName
+	IpHostHostName
+	IpHostRelayAgentOptions
+)
+
+func init() {
+	MeDefinitions[IPHostConfigData] = &MeDefinition{
+		Name: "IpHostConfigData",
+		Attributes: []AttributeDefinition{
+			{Name: "IpOptions", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "MacAddress", Size: 6, Access: AttrRead},
+			{Name: "OnuIdentifier", Size: 25, Access: AttrRead | AttrWrite},
+			{Name: "IpAddress", Size: 4, Access: AttrRead | AttrWrite},
+			{Name: "Mask", Size: 4, Access: AttrRead | AttrWrite},
+			{Name: "Gateway", Size: 4, Access: AttrRead | AttrWrite},
+			{Name: "PrimaryDns", Size: 4, Access: AttrRead | AttrWrite},
+			{Name: "SecondaryDns", Size: 4, Access: AttrRead | AttrWrite},
+			{Name: "CurrentAddress", Size: 4, Access: AttrRead},
+			{Name: "CurrentMask", Size: 4, Access: AttrRead},
+			{Name: "CurrentGateway", Size: 4, Access: AttrRead},
+			{Name: "CurrentPrimaryDns", Size: 4, Access: AttrRead},
+			{Name: "CurrentSecondaryDns", Size: 4, Access: AttrRead},
+			{Name: "DomainName", Size: 25, Access: AttrRead},
+			{Name: "HostName", Size: 25, Access: AttrRead},
+			{Name: "RelayAgentOptions", Size: 2, Access: AttrRead | AttrWrite},
+		},
+		Instances: ipHostInstances,
+		MibUpload: true,
+		Init:      initIpHost,
+	}
+}
+
+func ipHostInstances() []uint16 {
+	instances := make([]uint16, 0, Config.NumIpHost)
+	for i := 1; i <= Config.NumIpHost; i++ {
+		instances = append(instances, uint16(i))
+	}
+	return instances
+}
+
+// initIpHost gives each IP host a locally administered MAC address derived from the ONU and instance
+func initIpHost(key OnuKey, instance uint16, attrs MeAttributes) {
+	attrs[IpHostMacAddress] = []byte{0x0a, byte(key.OltId), byte(key.IntfId), byte(key.OnuId >> 8), byte(key.OnuId),
+		byte(instance)}
+}
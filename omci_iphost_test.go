@@ -0,0 +1,82 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// newTestIpHostOnu returns a freshly reset ONU with a single IP host, instance 0x0001
+func newTestIpHostOnu(t *testing.T) *testOnu {
+	defer func(numIpHost int) { Config.NumIpHost = numIpHost }(Config.NumIpHost)
+	Config.NumIpHost = 1
+	return newTestOnu(t)
+}
+
+func TestIpHostAddress(t *testing.T) {
+	onu := newTestIpHostOnu(t)
+
+	ip := []byte{192, 168, 1, 10}
+	if result, _ := onu.set(IPHostConfigData, 0x0001, attributeMaskBit(IpHostIpAddress), ip...); result != Success {
+		t.Fatalf("Set of the IP address got result %d", result)
+	}
+
+	values := onu.mustGet(IPHostConfigData, 0x0001, attributeMaskBit(IpHostMacAddress)|attributeMaskBit(IpHostIpAddress))
+	mac := []byte{0x0a, 0x00, byte(onu.intfId), 0x00, byte(onu.onuId), 0x01}
+	if !bytes.Equal(values[0:6], mac) {
+		t.Errorf("MAC address is %x, expected %x", values[0:6], mac)
+	}
+	if !bytes.Equal(values[6:10], ip) {
+		t.Errorf("IP address is %v, expected %v", values[6:10], ip)
+	}
+}
+
+func TestIpHostMacAddressReadOnly(t *testing.T) {
+	onu := newTestIpHostOnu(t)
+	result, failed := onu.set(IPHostConfigData, 0x0001, attributeMaskBit(IpHostMacAddress), 0, 1, 2, 3, 4, 5)
+	if result != AttributeFailure || failed != attributeMaskBit(IpHostMacAddress) {
+		t.Errorf("Set of the MAC address got result %d and failed mask %#04x", result, failed)
+	}
+}
+
+func TestIpHostMibUpload(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.CheckMibUpload = true
+	onu := newTestIpHostOnu(t)
+
+	// The attributes of the IP host don't fit in a single MibUploadNext
+	n := onu.startMibUpload()
+	if n <= numStaticMibUploads+1 {
+		t.Fatalf("The MIB upload has %d entries, expected the IP host to take several", n)
+	}
+	var uploaded uint16
+	for i := numStaticMibUploads; i < n; i++ {
+		resp, err := onu.mibUploadNext(i)
+		if err != nil {
+			t.Fatalf("MibUploadNext %d failed: %v", i, err)
+		}
+		if class := OmciClass(binary.BigEndian.Uint16(resp[8:10])); class != IPHostConfigData {
+			t.Fatalf("MibUploadNext %d reports %s, expected the IP host", i, class.PrettyPrint())
+		}
+		uploaded |= binary.BigEndian.Uint16(resp[12:14])
+	}
+	if uploaded != 0xFFFF {
+		t.Errorf("The MIB upload reports attributes %#04x, expected all of them", uploaded)
+	}
+}
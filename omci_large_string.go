@@ -0,0 +1,58 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+)
+
+// Large String attribute numbers, the parts are attributes LargeStringPart1 to LargeStringPart1+14
+const (
+	LargeStringNumberOfParts = 1
+	LargeStringPart1         = 2
+)
+
+const (
+	// largeStringMaxParts is the number of part attributes of a Large String
+	largeStringMaxParts = 15
+	// largeStringPartLength is the size of a part, a Get or Set carries one part at a time
+	largeStringPartLength = 25
+)
+
+func init() {
+	attributes := []AttributeDefinition{
+		{Name: "NumberOfParts", Size: 1, Access: AttrRead | AttrWrite},
+	}
+	for part := 1; part <= largeStringMaxParts; part++ {
+		attributes = append(attributes, AttributeDefinition{
+			Name: fmt.Sprintf("Part%d", part), Size: largeStringPartLength, Access: AttrRead | AttrWrite,
+		})
+	}
+
+	MeDefinitions[LargeString] = &MeDefinition{
+		Name:       "LargeString",
+		Attributes: attributes,
+		Validate:   validateLargeString,
+	}
+}
+
+func validateLargeString(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	if attrs[LargeStringNumberOfParts][0] > largeStringMaxParts {
+		return attributeMaskBit(LargeStringNumberOfParts)
+	}
+	return 0
+}
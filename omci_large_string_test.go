@@ -0,0 +1,58 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLargeStringParts(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(LargeString, 1, nil)
+
+	// A 59 character URL takes 3 parts
+	url := []byte("http://acs.example.com/cwmp/onu/provisioning/profile-01.xml")
+	var parts [][]byte
+	for start := 0; start < len(url); start += largeStringPartLength {
+		part := make([]byte, largeStringPartLength)
+		copy(part, url[start:])
+		parts = append(parts, part)
+	}
+	if result, _ := onu.set(LargeString, 1, attributeMaskBit(LargeStringNumberOfParts), byte(len(parts))); result != Success {
+		t.Fatalf("Set of the number of parts got result %d", result)
+	}
+	for i, part := range parts {
+		if result, _ := onu.set(LargeString, 1, attributeMaskBit(LargeStringPart1+i), part...); result != Success {
+			t.Fatalf("Set of part %d got result %d", i+1, result)
+		}
+	}
+
+	if n := onu.mustGet(LargeString, 1, attributeMaskBit(LargeStringNumberOfParts))[0]; n != 3 {
+		t.Errorf("Number of parts is %d, expected 3", n)
+	}
+	for i, part := range parts {
+		value := onu.mustGet(LargeString, 1, attributeMaskBit(LargeStringPart1+i))[:largeStringPartLength]
+		if !bytes.Equal(value, part) {
+			t.Errorf("Part %d is %q, expected %q", i+1, value, part)
+		}
+	}
+
+	if result, _ := onu.set(LargeString, 1, attributeMaskBit(LargeStringNumberOfParts), largeStringMaxParts+1); result != ParameterError {
+		t.Errorf("Set of %d parts got result %d, expected %d", largeStringMaxParts+1, result, ParameterError)
+	}
+}
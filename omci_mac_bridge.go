@@ -0,0 +1,122 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"sort"
+)
+
+// MAC Bridge Service Profile attribute numbers
+const (
+	MacBridgeSpanningTreeInd = iota + 1
+	MacBridgeLearningInd
+	MacBridgePortBridgingInd
+	MacBridgePriority
+	MacBridgeMaxAge
+	MacBridgeHelloTime
+	MacBridgeForwardDelay
+	MacBridgeUnknownMacAddressDiscard
+	MacBridgeMacLearningDepth
+	MacBridgeDynamicFilteringAgeingTime
+)
+
+// MAC Bridge Port Configuration Data attribute numbers
+const (
+	MacBridgePortBridgeIdPointer = iota + 1
+	MacBridgePortPortNum
+	MacBridgePortTpType
+	MacBridgePortTpPointer
+	MacBridgePortPortPriority
+	MacBridgePortPortPathCost
+	MacBridgePortPortSpanningTreeInd
+	MacBridgePortEncapsulationMethod
+	MacBridgePortLanFcsInd
+	MacBridgePortPortMacAddress
+	MacBridgePortOutboundTdPointer
+	MacBridgePortInboundTdPointer
+	MacBridgePortMacLearningDepth
+)
+
+func init() {
+	MeDefinitions[MacBridgeServiceProfile] = &MeDefinition{
+		Name: "MacBridgeServiceProfile",
+		Attributes: []AttributeDefinition{
+			{Name: "SpanningTreeInd", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "LearningInd", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PortBridgingInd", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "Priority", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MaxAge", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "HelloTime", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "ForwardDelay", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "UnknownMacAddressDiscard", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MacLearningDepth", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "DynamicFilteringAgeingTime", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+		Children: macBridgePorts,
+	}
+
+	MeDefinitions[MacBridgePortConfigData] = &MeDefinition{
+		Name: "MacBridgePortConfigurationData",
+		Attributes: []AttributeDefinition{
+			{Name: "BridgeIdPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PortNum", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "TpType", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "TpPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PortPriority", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PortPathCost", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PortSpanningTreeInd", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "EncapsulationMethod", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "LanFcsInd", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PortMacAddress", Size: 6, Access: AttrRead},
+			{Name: "OutboundTdPointer", Size: 2, Access: AttrRead | AttrWrite},
+			{Name: "InboundTdPointer", Size: 2, Access: AttrRead | AttrWrite},
+			{Name: "MacLearningDepth", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+		Validate: validateMacBridgePortConfigData,
+	}
+}
+
+func validateMacBridgePortConfigData(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	var failed uint16
+
+	if _, ok := state.getMe(MacBridgeServiceProfile, attrs.uint16(MacBridgePortBridgeIdPointer)); !ok {
+		failed |= attributeMaskBit(MacBridgePortBridgeIdPointer)
+	}
+
+	for _, index := range []int{MacBridgePortOutboundTdPointer, MacBridgePortInboundTdPointer} {
+		td := attrs.uint16(index)
+		if _, ok := state.getMe(TrafficDescriptor, td); !isNullPointer(td) && !ok {
+			failed |= attributeMaskBit(index)
+		}
+	}
+
+	return failed
+}
+
+// macBridgePorts returns the ports of a MAC bridge, in instance order
+func macBridgePorts(state *OnuOmciState, instance uint16) []OmciMessageIdentifier {
+	var ports []OmciMessageIdentifier
+	for port, attrs := range state.mib[MacBridgePortConfigData] {
+		if attrs.uint16(MacBridgePortBridgeIdPointer) == instance {
+			ports = append(ports, OmciMessageIdentifier{Class: MacBridgePortConfigData, Instance: port})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Instance < ports[j].Instance
+	})
+	return ports
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// MAC Bridge PM History Data attribute numbers
+const (
+	MacBridgePmIntervalEndTime = iota + 1
+	MacBridgePmThresholdDataId
+	MacBridgePmLearningEntryDiscardCount
+)
+
+// The MAC Bridge PM History Data instances share the id of the MAC Bridge Service Profile they monitor
+func init() {
+	MeDefinitions[MacBridgePMHistoryData] = &MeDefinition{
+		Name: "MacBridgePmHistoryData",
+		Attributes: []AttributeDefinition{
+			{Name: "IntervalEndTime", Size: 1, Access: AttrRead},
+			{Name: "ThresholdData12Id", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "BridgeLearningEntryDiscardCount", Size: 4, Access: AttrRead},
+		},
+		ThresholdDataId: MacBridgePmThresholdDataId,
+		Tcas: []TcaDefinition{
+			{Attribute: MacBridgePmLearningEntryDiscardCount, Alarm: 0, Threshold: 1},
+		},
+	}
+}
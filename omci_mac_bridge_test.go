@@ -0,0 +1,72 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+)
+
+// newTestBridge creates a MAC bridge with two ports on an ONU
+func newTestBridge(onu *testOnu) {
+	onu.t.Helper()
+	onu.mustCreate(MacBridgeServiceProfile, 0x0201, nil)
+	for _, port := range []uint16{0x2102, 0x2103} {
+		onu.mustCreate(MacBridgePortConfigData, port, map[int][]byte{
+			MacBridgePortBridgeIdPointer: {0x02, 0x01},
+			MacBridgePortPortNum:         {byte(port)},
+		})
+	}
+}
+
+func TestMacBridgeCascadeDelete(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.CascadeDelete = true
+	onu := newTestOnu(t)
+	newTestBridge(onu)
+
+	if result := onu.result(onu.send(Delete, MacBridgeServiceProfile, 0x0201, nil)); result != Success {
+		t.Fatalf("Delete of the bridge got result %d, expected %d", result, Success)
+	}
+	for _, port := range []uint16{0x2102, 0x2103} {
+		if result, _ := onu.get(MacBridgePortConfigData, port, 0x8000); result != UnknownInstance {
+			t.Errorf("Get of port %#04x got result %d, expected %d", port, result, UnknownInstance)
+		}
+	}
+}
+
+func TestMacBridgeDeleteWithPorts(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.CascadeDelete = false
+	onu := newTestOnu(t)
+	newTestBridge(onu)
+
+	if result := onu.result(onu.send(Delete, MacBridgeServiceProfile, 0x0201, nil)); result != DeviceBusy {
+		t.Errorf("Delete of the bridge got result %d, expected %d", result, DeviceBusy)
+	}
+	onu.mustGet(MacBridgeServiceProfile, 0x0201, 0x8000)
+	onu.mustGet(MacBridgePortConfigData, 0x2102, 0x8000)
+
+	// Once its ports are deleted the bridge can be deleted too
+	for _, port := range []uint16{0x2102, 0x2103} {
+		if result := onu.result(onu.send(Delete, MacBridgePortConfigData, port, nil)); result != Success {
+			t.Fatalf("Delete of port %#04x got result %d", port, result)
+		}
+	}
+	if result := onu.result(onu.send(Delete, MacBridgeServiceProfile, 0x0201, nil)); result != Success {
+		t.Errorf("Delete of the bridge without ports got result %d, expected %d", result, Success)
+	}
+}
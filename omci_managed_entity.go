@@ -0,0 +1,146 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// Managed Entity attribute numbers
+const (
+	ManagedEntityName = iota + 1
+	ManagedEntityAttributesTable
+	ManagedEntityAccess
+	ManagedEntityAlarmsTable
+	ManagedEntityAvcsTable
+	ManagedEntityActions
+	ManagedEntityInstancesTable
+	ManagedEntitySupport
+)
+
+// Managed Entity access values
+const (
+	createdByOnu uint8 = 1
+	createdByOlt uint8 = 2
+)
+
+// The Managed Entity MEs describe the ME classes in MeDefinitions, their instance is the ME class
+func init() {
+	MeDefinitions[ManagedEntity] = &MeDefinition{
+		Name: "ManagedEntity",
+		Attributes: []AttributeDefinition{
+			{Name: "Name", Size: 25, Access: AttrRead, Value: managedEntityName},
+			{Name: "AttributesTable", Size: 2, Access: AttrRead, Table: true, Value: managedEntityAttributes},
+			{Name: "Access", Size: 1, Access: AttrRead, Value: managedEntityAccess},
+			{Name: "AlarmsTable", Size: 1, Access: AttrRead, Table: true, Value: managedEntityAlarms},
+			{Name: "AvcsTable", Size: 1, Access: AttrRead, Table: true},
+			{Name: "Actions", Size: 4, Access: AttrRead, Value: managedEntityActions},
+			{Name: "InstancesTable", Size: 2, Access: AttrRead, Table: true, Value: managedEntityInstances},
+			// Supported
+			{Name: "Support", Size: 1, Access: AttrRead, Default: []byte{0x01}},
+		},
+		Instances: managedEntityClasses,
+		Computed:  true,
+	}
+}
+
+func managedEntityClasses() []uint16 {
+	classes := make([]uint16, 0, len(MeDefinitions))
+	for class := range MeDefinitions {
+		classes = append(classes, uint16(class))
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i] < classes[j] })
+	return classes
+}
+
+// attributeMeInstance returns the instance of the Attribute ME describing an attribute of an ME class.
+// The class is encoded in the upper 12 bits of the instance and the attribute in the lower 4, so the
+// attributes of the classes above 4095 and the attributes past the 16th have no Attribute ME
+func attributeMeInstance(class OmciClass, index int) (uint16, bool) {
+	if class > 0x0FFF || index < 1 || index > 16 {
+		return 0, false
+	}
+	return uint16(class)<<4 | uint16(index-1), true
+}
+
+func managedEntityName(state *OnuOmciState, instance uint16) []byte {
+	name := make([]byte, 25)
+	copy(name, MeDefinitions[OmciClass(instance)].Name)
+	return name
+}
+
+func managedEntityAttributes(state *OnuOmciState, instance uint16) []byte {
+	def := MeDefinitions[OmciClass(instance)]
+	table := make([]byte, 0, 2*len(def.Attributes))
+	for i := range def.Attributes {
+		if attribute, ok := attributeMeInstance(OmciClass(instance), i+1); ok {
+			table = append(table, byte(attribute>>8), byte(attribute&0xFF))
+		}
+	}
+	return table
+}
+
+func managedEntityAccess(state *OnuOmciState, instance uint16) []byte {
+	if MeDefinitions[OmciClass(instance)].Instances != nil {
+		return []byte{createdByOnu}
+	}
+	return []byte{createdByOlt}
+}
+
+func managedEntityAlarms(state *OnuOmciState, instance uint16) []byte {
+	def := MeDefinitions[OmciClass(instance)]
+	table := make([]byte, 0, len(def.Tcas))
+	for _, tca := range def.Tcas {
+		table = append(table, byte(tca.Alarm))
+	}
+	return table
+}
+
+// managedEntityActions returns the message types supported by an ME class, the least
+// significant bit being message type 0
+func managedEntityActions(state *OnuOmciState, instance uint16) []byte {
+	def := MeDefinitions[OmciClass(instance)]
+	actions := uint32(1) << uint(Get)
+	if def.Instances == nil {
+		actions |= 1<<uint(Create) | 1<<uint(Delete)
+	}
+	if len(def.Tcas) != 0 {
+		actions |= 1 << uint(GetCurrentData)
+	}
+	for _, attrDef := range def.Attributes {
+		if attrDef.Access&AttrWrite != 0 {
+			actions |= 1 << uint(Set)
+		}
+		if attrDef.Table {
+			actions |= 1 << uint(GetNext)
+		}
+	}
+
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, actions)
+	return b
+}
+
+func managedEntityInstances(state *OnuOmciState, instance uint16) []byte {
+	instances := state.meInstances(OmciClass(instance))
+	table := make([]byte, 2*len(instances))
+	for i, id := range instances {
+		binary.BigEndian.PutUint16(table[2*i:], id)
+	}
+	return table
+}
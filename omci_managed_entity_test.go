@@ -0,0 +1,103 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestManagedEntityClasses(t *testing.T) {
+	onu := newTestOnu(t)
+
+	// Every modeled class is described by the Managed Entity ME of the same instance
+	for class, def := range MeDefinitions {
+		values := onu.mustGet(ManagedEntity, uint16(class), attributeMaskBit(ManagedEntityName))
+		// The name is truncated to the 25 bytes of the attribute
+		expected := def.Name
+		if len(expected) > 25 {
+			expected = expected[:25]
+		}
+		if name := strings.TrimRight(string(values[:25]), "\x00"); name != expected {
+			t.Errorf("Name of class %d is %q, expected %q", class, name, expected)
+		}
+	}
+	if result, _ := onu.get(ManagedEntity, uint16(unmodeledClass), attributeMaskBit(ManagedEntityName)); result != UnknownInstance {
+		t.Errorf("Get of an unmodeled class got result %d, expected %d", result, UnknownInstance)
+	}
+}
+
+func TestManagedEntityAttributesTable(t *testing.T) {
+	onu := newTestOnu(t)
+
+	table := onu.readTable(ManagedEntity, uint16(GEMPortNetworkCTP), ManagedEntityAttributesTable)
+	numAttributes := len(MeDefinitions[GEMPortNetworkCTP].Attributes)
+	if len(table) != 2*numAttributes {
+		t.Fatalf("Attributes table is %d bytes, expected %d", len(table), 2*numAttributes)
+	}
+	for i := 0; i < numAttributes; i++ {
+		if attribute := binary.BigEndian.Uint16(table[2*i:]); attribute != uint16(GEMPortNetworkCTP)<<4|uint16(i) {
+			t.Errorf("Attribute %d is %#04x", i+1, attribute)
+		}
+	}
+
+	// The ONU creates the GEM ports and the OLT the ANI-G
+	if values := onu.mustGet(ManagedEntity, uint16(GEMPortNetworkCTP), attributeMaskBit(ManagedEntityAccess)); values[0] != createdByOlt {
+		t.Errorf("Access of the GEM port is %d, expected %d", values[0], createdByOlt)
+	}
+	if values := onu.mustGet(ManagedEntity, uint16(ANIG), attributeMaskBit(ManagedEntityAccess)); values[0] != createdByOnu {
+		t.Errorf("Access of the ANI-G is %d, expected %d", values[0], createdByOnu)
+	}
+}
+
+func TestAttributeDescriptor(t *testing.T) {
+	onu := newTestOnu(t)
+	instance, _ := attributeMeInstance(GEMPortNetworkCTP, GemPortCtpPortId)
+
+	values := onu.mustGet(AttributeME, instance, attributeMaskBit(AttributeMeName))
+	if name := strings.TrimRight(string(values[:25]), "\x00"); name != "PortId" {
+		t.Errorf("Name of the attribute is %q, expected %q", name, "PortId")
+	}
+
+	mask := attributeMaskBit(AttributeMeSize) | attributeMaskBit(AttributeMeAccess) | attributeMaskBit(AttributeMeFormat)
+	values = onu.mustGet(AttributeME, instance, mask)
+	if size := binary.BigEndian.Uint16(values); size != 2 {
+		t.Errorf("Size of the Port-ID is %d, expected 2", size)
+	}
+	// Read/write and set-by-create
+	if values[2] != 6 {
+		t.Errorf("Access of the Port-ID is %d, expected 6", values[2])
+	}
+	if values[3] != attributeFormatUnsignedInteger {
+		t.Errorf("Format of the Port-ID is %d, expected %d", values[3], attributeFormatUnsignedInteger)
+	}
+}
+
+func TestComputedMesNotStored(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustGet(AttributeME, uint16(GEMPortNetworkCTP)<<4, attributeMaskBit(AttributeMeName))
+
+	OnuOmciStateMapLock.RLock()
+	defer OnuOmciStateMapLock.RUnlock()
+	state := OnuOmciStateMap[OnuKey{0, onu.intfId, onu.onuId}]
+	for _, class := range []OmciClass{ManagedEntity, AttributeME} {
+		if instances := len(state.mib[class]); instances != 0 {
+			t.Errorf("%d instances of class %d are stored, expected none", instances, class)
+		}
+	}
+}
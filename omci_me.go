@@ -0,0 +1,546 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"sync"
+)
+
+// AttributeAccess flags how the OLT may access an ME attribute
+type AttributeAccess uint8
+
+const (
+	AttrRead        AttributeAccess = 0x01
+	AttrWrite       AttributeAccess = 0x02
+	AttrSetByCreate AttributeAccess = 0x04
+)
+
+type AttributeDefinition struct {
+	Name    string
+	Size    int // Size of an entry for a table attribute
+	Access  AttributeAccess
+	Default []byte // Zero-filled if not set
+	// Range bounds the unsigned value written by a Create or Set between Min and Max, a zero Max
+	// standing for the largest value of the attribute size
+	Range    bool
+	Min, Max uint64
+	// Table attributes are read with a Get returning the table size followed by GetNext requests
+	Table bool
+	// Value, if set, computes the attribute value instead of reading the stored one
+	Value func(state *OnuOmciState, instance uint16) []byte
+	// SetEntry, if set, returns a table once an entry written by a Set is added to, replaced in or
+	// removed from it, or false if the entry is rejected. Entries are appended by default.
+	SetEntry func(table []byte, entry []byte) ([]byte, bool)
+}
+
+// defaultValue returns the value of an attribute not set by the OLT, tables are empty unless a Default is given
+func (d AttributeDefinition) defaultValue() []byte {
+	if d.Table {
+		return append([]byte{}, d.Default...)
+	}
+	value := make([]byte, d.Size)
+	copy(value, d.Default)
+	return value
+}
+
+// bounds returns the lowest and highest values of an attribute with a Range
+func (d AttributeDefinition) bounds() (uint64, uint64) {
+	if d.Max != 0 {
+		return d.Min, d.Max
+	}
+	if d.Size >= 8 {
+		return d.Min, math.MaxUint64
+	}
+	return d.Min, 1<<uint(8*d.Size) - 1
+}
+
+// inRange reports whether a value written by the OLT satisfies the Range of the attribute
+func (d AttributeDefinition) inRange(value []byte) bool {
+	if !d.Range || d.Table {
+		return true
+	}
+	min, max := d.bounds()
+	v := counterValue(value)
+	return v >= min && v <= max
+}
+
+// MeAttributes holds the attribute values of an ME instance, indexed by attribute number (1-16)
+type MeAttributes map[int][]byte
+
+// MeDefinition describes an ME class whose instances are stored by the simulator
+type MeDefinition struct {
+	Name string
+	// Attributes[0] is attribute 1, the most significant bit of an attribute mask
+	Attributes []AttributeDefinition
+	// Validate, if set, returns the mask of the attributes holding invalid values
+	// when an instance is created or set
+	Validate func(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16
+	// Tcas are the threshold crossing alerts of a PM ME
+	Tcas []TcaDefinition
+	// Counters are the counter attributes of a PM ME without a TCA
+	Counters []int
+	// ThresholdDataId, if set, is the attribute of a PM ME pointing to its Threshold Data 1 and 2
+	// instances, the TCAs of the other PM MEs are only checked against the values of SetPmThreshold
+	ThresholdDataId int
+	// Instances, if set, returns the instances the ONU creates by itself on a MIB reset
+	Instances func() []uint16
+	// MibUpload reports the instances created by the ONU in the MIB upload, after the MEs every ONU reports
+	MibUpload bool
+	// Computed MEs describe the simulator rather than an ONU: their Instances are served on demand, with
+	// their default and computed attribute values, instead of being stored for every ONU
+	Computed bool
+	// Init, if set, fills in the ONU specific attribute values of the instances created by the ONU
+	Init func(key OnuKey, instance uint16, attrs MeAttributes)
+	// Children, if set, returns the MEs pointing to an instance, see Config.CascadeDelete
+	Children func(state *OnuOmciState, instance uint16) []OmciMessageIdentifier
+}
+
+// MeDefinitions are the ME classes stored by the simulator, each ME registers itself from init().
+// The Get, Set and Delete of an instance of these classes which is not created are answered with
+// UnknownInstance, as an ONU would, where the simulator answered Success before storing them.
+var MeDefinitions = map[OmciClass]*MeDefinition{}
+
+const (
+	// Get responses carry the attribute values between the attribute mask and the trailing masks
+	getAttributesStart = 11
+	getAttributesEnd   = 36
+	// GetNext responses carry a part of a table after the attribute mask
+	getNextAttributesLength = 29
+)
+
+func attributeMaskBit(index int) uint16 {
+	return 0x8000 >> uint(index-1)
+}
+
+func isNullPointer(pointer uint16) bool {
+	return pointer == 0x0000 || pointer == 0xFFFF
+}
+
+func (a MeAttributes) uint16(index int) uint16 {
+	return binary.BigEndian.Uint16(a[index])
+}
+
+func (s *OnuOmciState) getMe(class OmciClass, instance uint16) (MeAttributes, bool) {
+	if def, ok := MeDefinitions[class]; ok && def.Computed {
+		return computedMe(def, instance)
+	}
+	attrs, ok := s.mib[class][instance]
+	return attrs, ok
+}
+
+// computedMe returns the attribute values of an instance of a Computed ME class
+func computedMe(def *MeDefinition, instance uint16) (MeAttributes, bool) {
+	for _, id := range def.Instances() {
+		if id != instance {
+			continue
+		}
+		attrs := MeAttributes{}
+		for i, attrDef := range def.Attributes {
+			attrs[i+1] = attrDef.defaultValue()
+		}
+		return attrs, true
+	}
+	return nil, false
+}
+
+// meInstances returns the instances of an ME class, in id order
+func (s *OnuOmciState) meInstances(class OmciClass) []uint16 {
+	if def, ok := MeDefinitions[class]; ok && def.Computed {
+		return def.Instances()
+	}
+	instances := make([]uint16, 0, len(s.mib[class]))
+	for instance := range s.mib[class] {
+		instances = append(instances, instance)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i] < instances[j]
+	})
+	return instances
+}
+
+// firstMeInstance returns the instance of an ME class having the lowest id
+func (s *OnuOmciState) firstMeInstance(class OmciClass) (uint16, bool) {
+	instances := s.meInstances(class)
+	if len(instances) == 0 {
+		return 0, false
+	}
+	return instances[0], true
+}
+
+// DefaultProvider returns attribute values, indexed by attribute number, replacing the Default of the
+// attribute definitions of an ME instance of an ONU
+type DefaultProvider func(oltId int, intfId uint32, onuId uint32, instance uint16) map[int][]byte
+
+var defaultProviders = map[OmciClass]DefaultProvider{}
+var defaultProvidersLock = sync.RWMutex{}
+
+// RegisterDefaultProvider sets the provider of the default attribute values of the instances of an ME class
+// in MeDefinitions, as created by the ONU or by the OLT. The set-by-create attributes are still the ones
+// of the Create, and the values are truncated or zero padded to the attribute size. A nil provider
+// restores the built-in defaults.
+//
+// The provider is called with OnuOmciStateMapLock held: it must not call the exported functions of
+// the simulator, such as OmciSim or the getters of the ONU state, which would deadlock.
+func RegisterDefaultProvider(class OmciClass, provider DefaultProvider) {
+	defaultProvidersLock.Lock()
+	defer defaultProvidersLock.Unlock()
+	if provider == nil {
+		delete(defaultProviders, class)
+		return
+	}
+	defaultProviders[class] = provider
+}
+
+// defaultAttributes returns the attribute values of a new ME instance, see RegisterDefaultProvider
+func (s *OnuOmciState) defaultAttributes(class OmciClass, instance uint16) MeAttributes {
+	def := MeDefinitions[class]
+	attrs := MeAttributes{}
+	for i, attrDef := range def.Attributes {
+		attrs[i+1] = attrDef.defaultValue()
+	}
+
+	defaultProvidersLock.RLock()
+	provider, ok := defaultProviders[class]
+	defaultProvidersLock.RUnlock()
+	if !ok {
+		return attrs
+	}
+	for index, value := range provider(s.key.OltId, s.key.IntfId, s.key.OnuId, instance) {
+		if index < 1 || index > len(def.Attributes) {
+			continue
+		}
+		if def.Attributes[index-1].Table {
+			attrs[index] = append([]byte{}, value...)
+			continue
+		}
+		copy(attrs[index], value)
+	}
+	return attrs
+}
+
+// createOnuMes creates the ME instances owned by the ONU, with their default attribute values
+func (s *OnuOmciState) createOnuMes() {
+	for class, def := range MeDefinitions {
+		if def.Instances == nil || def.Computed {
+			continue
+		}
+		for _, instance := range def.Instances() {
+			attrs := s.defaultAttributes(class, instance)
+			if def.Init != nil {
+				def.Init(s.key, instance, attrs)
+			}
+			if _, ok := s.mib[class]; !ok {
+				s.mib[class] = map[uint16]MeAttributes{}
+			}
+			s.mib[class][instance] = attrs
+		}
+	}
+}
+
+func (s *OnuOmciState) createMe(class OmciClass, instance uint16, content OmciContent) (OmciResult, uint16) {
+	def := MeDefinitions[class]
+	if def.Instances != nil {
+		// The OLT cannot create nor delete the MEs owned by the ONU
+		return NotSupported, 0
+	}
+	if _, ok := s.getMe(class, instance); ok {
+		return InstanceExists, 0
+	}
+
+	// The contents of a Create are the set-by-create attributes, in attribute order
+	r := NewContentReader(content[:])
+	attrs := s.defaultAttributes(class, instance)
+	var outOfRange uint16
+	for i, attrDef := range def.Attributes {
+		if attrDef.Access&AttrSetByCreate != 0 {
+			b, err := r.ReadBytes(attrDef.Size)
+			if err != nil {
+				return ProcessingError, 0
+			}
+			copy(attrs[i+1], b)
+			if !attrDef.inRange(attrs[i+1]) {
+				outOfRange |= attributeMaskBit(i + 1)
+			}
+		}
+	}
+
+	if outOfRange != 0 {
+		return ParameterError, outOfRange
+	}
+
+	if def.Validate != nil {
+		if failed := def.Validate(s, instance, attrs); failed != 0 {
+			return ParameterError, failed
+		}
+	}
+
+	if _, ok := s.mib[class]; !ok {
+		s.mib[class] = map[uint16]MeAttributes{}
+	}
+	s.mib[class][instance] = attrs
+	return Success, 0
+}
+
+// tableAttributesMask returns the table attributes of an ME class written by a Set
+func tableAttributesMask(class OmciClass, content OmciContent) uint16 {
+	return tableAttributes(class, NewContentReader(content[:]).ReadMask())
+}
+
+// tableAttributes returns the table attributes of an ME class among the attributes in mask
+func tableAttributes(class OmciClass, mask uint16) uint16 {
+	def, ok := MeDefinitions[class]
+	if !ok {
+		return 0
+	}
+
+	var tables uint16
+	for i, attrDef := range def.Attributes {
+		if attrDef.Table && mask&attributeMaskBit(i+1) != 0 {
+			tables |= attributeMaskBit(i + 1)
+		}
+	}
+	return tables
+}
+
+// lockForGet locks OnuOmciStateMap for a Get of the attributes in mask of an ME class and returns the function
+// unlocking it: reading a table attribute takes a snapshot of it, the other Gets only read the state
+func lockForGet(class OmciClass, mask uint16) func() {
+	if tableAttributes(class, mask) == 0 {
+		OnuOmciStateMapLock.RLock()
+		return OnuOmciStateMapLock.RUnlock
+	}
+	OnuOmciStateMapLock.Lock()
+	return OnuOmciStateMapLock.Unlock
+}
+
+func (s *OnuOmciState) setMe(class OmciClass, instance uint16, content OmciContent) (OmciResult, uint16) {
+	def := MeDefinitions[class]
+	current, ok := s.getMe(class, instance)
+	if !ok {
+		return UnknownInstance, 0
+	}
+
+	r := NewContentReader(content[:])
+	mask := r.ReadMask()
+
+	// Work on a copy so that a rejected Set leaves the instance untouched
+	attrs := MeAttributes{}
+	for index, value := range current {
+		attrs[index] = value
+	}
+	// The attributes which are read-only or written out of range are left untouched and reported
+	// in the attribute execution mask, the other attributes are still written
+	var failed uint16
+	for index := 1; index <= 16; index++ {
+		if mask&attributeMaskBit(index) == 0 {
+			continue
+		}
+		if index > len(def.Attributes) {
+			// Without its size, the values of the following attributes cannot be found
+			return ParameterError, attributeMaskBit(index)
+		}
+		attrDef := def.Attributes[index-1]
+		b, err := r.ReadBytes(attrDef.Size)
+		if err != nil {
+			// The mask claims more attributes than the contents carry
+			return ProcessingError, 0
+		}
+		if attrDef.Access&AttrWrite == 0 {
+			failed |= attributeMaskBit(index)
+			continue
+		}
+		if !attrDef.Table {
+			if !attrDef.inRange(b) {
+				failed |= attributeMaskBit(index)
+				continue
+			}
+			attrs[index] = append([]byte{}, b...)
+			continue
+		}
+
+		// A Set writes one entry of a table
+		if attrDef.SetEntry == nil {
+			attrs[index] = append(append([]byte{}, attrs[index]...), b...)
+			continue
+		}
+		table, ok := attrDef.SetEntry(append([]byte{}, attrs[index]...), b)
+		if !ok {
+			return ParameterError, attributeMaskBit(index)
+		}
+		attrs[index] = table
+	}
+
+	if def.Validate != nil {
+		if invalid := def.Validate(s, instance, attrs); invalid != 0 {
+			return ParameterError, invalid
+		}
+	}
+
+	if !def.Computed {
+		s.mib[class][instance] = attrs
+	}
+	if failed != 0 {
+		return AttributeFailure, failed
+	}
+	return Success, 0
+}
+
+// getMeAttributes fills pkt with the requested attributes of an ME instance, attributes which
+// don't fit in the response are reported in the attribute execution mask
+func (s *OnuOmciState) getMeAttributes(class OmciClass, instance uint16, mask uint16, pkt []byte) OmciResult {
+	attrs, ok := s.getMe(class, instance)
+	if !ok {
+		pkt[9] = 0x00
+		pkt[10] = 0x00
+		return UnknownInstance
+	}
+	return s.fillMeAttributes(class, instance, attrs, mask, pkt)
+}
+
+func (s *OnuOmciState) fillMeAttributes(class OmciClass, instance uint16, attrs MeAttributes, mask uint16, pkt []byte) OmciResult {
+	values, served, unsupported, failed := s.readMeAttributes(class, instance, attrs, mask, getAttributesEnd-getAttributesStart)
+	copy(pkt[getAttributesStart:], values)
+
+	binary.BigEndian.PutUint16(pkt[9:11], served)
+	binary.BigEndian.PutUint16(pkt[getAttributesEnd:getAttributesEnd+2], unsupported)
+	binary.BigEndian.PutUint16(pkt[getAttributesEnd+2:getAttributesEnd+4], failed)
+	if unsupported != 0 || failed != 0 {
+		return AttributeFailure
+	}
+	return Success
+}
+
+// readMeAttributes returns the values of the attributes in mask fitting in limit bytes, along with
+// the masks of the attributes served, not supported and not fitting
+func (s *OnuOmciState) readMeAttributes(class OmciClass, instance uint16, attrs MeAttributes, mask uint16,
+	limit int) (values []byte, served, unsupported, failed uint16) {
+	def := MeDefinitions[class]
+	for index := 1; index <= 16; index++ {
+		bit := attributeMaskBit(index)
+		if mask&bit == 0 {
+			continue
+		}
+		if index > len(def.Attributes) || def.Attributes[index-1].Access&AttrRead == 0 {
+			unsupported |= bit
+			continue
+		}
+		value := attrs[index]
+		if def.Attributes[index-1].Value != nil {
+			value = def.Attributes[index-1].Value(s, instance)
+		}
+		if def.Attributes[index-1].Table {
+			// The table is read from a snapshot taken by the Get, its response carries the table size
+			s.saveTableSnapshot(class, instance, index, value)
+			size := make([]byte, 4)
+			binary.BigEndian.PutUint32(size, uint32(len(value)))
+			value = size
+		}
+		if len(values)+len(value) > limit {
+			failed |= bit
+			continue
+		}
+		values = append(values, value...)
+		served |= bit
+	}
+	return values, served, unsupported, failed
+}
+
+func (s *OnuOmciState) saveTableSnapshot(class OmciClass, instance uint16, index int, table []byte) {
+	id := OmciMessageIdentifier{Class: class, Instance: instance}
+	if _, ok := s.tableSnapshots[id]; !ok {
+		s.tableSnapshots[id] = map[int][]byte{}
+	}
+	s.tableSnapshots[id][index] = append([]byte{}, table...)
+}
+
+// getNextMeAttribute fills pkt with the part of a table attribute snapshot selected by the
+// command sequence number of a GetNext
+func (s *OnuOmciState) getNextMeAttribute(class OmciClass, instance uint16, content OmciContent, pkt []byte) OmciResult {
+	def := MeDefinitions[class]
+	if _, ok := s.getMe(class, instance); !ok {
+		return UnknownInstance
+	}
+
+	r := NewContentReader(content[:])
+	mask := r.ReadMask()
+	sequenceNumber, _ := r.ReadUint16()
+
+	// Exactly one table attribute is read at a time
+	index := 0
+	for i := 1; i <= 16; i++ {
+		if mask == attributeMaskBit(i) {
+			index = i
+		}
+	}
+	if index == 0 || index > len(def.Attributes) || !def.Attributes[index-1].Table {
+		return ParameterError
+	}
+	table, ok := s.tableSnapshots[OmciMessageIdentifier{Class: class, Instance: instance}][index]
+	if !ok {
+		return ParameterError
+	}
+
+	start := int(sequenceNumber) * getNextAttributesLength
+	if start >= len(table) {
+		return ParameterError
+	}
+	copy(pkt[getAttributesStart:getAttributesStart+getNextAttributesLength], table[start:])
+	binary.BigEndian.PutUint16(pkt[9:11], mask)
+	return Success
+}
+
+func (s *OnuOmciState) deleteMe(class OmciClass, instance uint16) OmciResult {
+	if MeDefinitions[class].Instances != nil {
+		return NotSupported
+	}
+	if _, ok := s.getMe(class, instance); !ok {
+		return UnknownInstance
+	}
+	if def := MeDefinitions[class]; def.Children != nil {
+		children := def.Children(s, instance)
+		if len(children) != 0 && !Config.CascadeDelete {
+			return DeviceBusy
+		}
+		for _, child := range children {
+			s.deleteMe(child.Class, child.Instance)
+		}
+	}
+	delete(s.mib[class], instance)
+
+	// Nothing is left for a new instance with the same id to inherit, such as the alarms raised
+	// by the deleted one which would keep the new instance from raising them again
+	id := OmciMessageIdentifier{Class: class, Instance: instance}
+	delete(s.pmCurrent, id)
+	delete(s.pmThresholds, id)
+	delete(s.tableSnapshots, id)
+	delete(s.alarms, id)
+	return Success
+}
+
+// writeDefaultAttribute writes the default value of an attribute of an ME class in MeDefinitions at pos, in
+// place of the per-attribute handlers of the MEs served before MeDefinitions
+func writeDefaultAttribute(class OmciClass, index int, pos *uint, pkt []byte) ([]byte, error) {
+	value := MeDefinitions[class].Attributes[index-1].defaultValue()
+	if int(*pos) < len(pkt) {
+		copy(pkt[*pos:], value)
+	}
+	*pos += uint(len(value))
+	return pkt, nil
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+)
+
+func TestSetContentOverrun(t *testing.T) {
+	onu := newTestVeipOnu(t)
+	onu.mustCreate(ExtendedVlanTagging, 0x0501, map[int][]byte{
+		ExtVlanAssociationType:     {10},
+		ExtVlanAssociatedMePointer: {0x05, 0x01},
+	})
+
+	// The TPIDs, downstream mode, associated ME pointer and DSCP mapping need 31 bytes,
+	// one more than the contents of a baseline Set carry
+	mask := attributeMaskBit(ExtVlanInputTpid) | attributeMaskBit(ExtVlanOutputTpid) |
+		attributeMaskBit(ExtVlanDownstreamMode) | attributeMaskBit(ExtVlanAssociatedMePointer) |
+		attributeMaskBit(ExtVlanDscpToPbitMapping)
+	values := make([]byte, 30)
+	if result, _ := onu.set(ExtendedVlanTagging, 0x0501, mask, values...); result != ProcessingError {
+		t.Errorf("Set of %d bytes of attributes got result %d, expected %d", 31, result, ProcessingError)
+	}
+
+	// Nothing was written
+	tpid := onu.mustGet(ExtendedVlanTagging, 0x0501, attributeMaskBit(ExtVlanInputTpid))
+	if tpid[0] != 0x81 || tpid[1] != 0x00 {
+		t.Errorf("Input TPID is %x after a failed Set, expected 8100", tpid[:2])
+	}
+}
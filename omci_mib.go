@@ -16,7 +16,140 @@
 
 package core
 
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
 const NumMibUploadsHigherByte byte = 0x01
-const NumMibUploadsLowerByte byte = 0x23
+const NumMibUploadsLowerByte byte = 0x24
 const NumPriorQPerTcont = 0x08 // NumPriorQPerTcont is the number of priority queues associated with a single tcont
+const NumTcont = 0x08          // NumTcont is the number of T-CONTs reported in the MIB upload
+
+// numStaticMibUploads is the number of MibUploadNext responses reporting the MEs every ONU has, the ones
+// reporting the MEs depending on the ONU configuration follow, see MeDefinition.MibUpload
+const numStaticMibUploads = int(NumMibUploadsHigherByte)<<8 | int(NumMibUploadsLowerByte)
+
+// The attribute values of a MibUploadNext response follow the class, instance and attribute mask
+const (
+	mibUploadValuesStart = 14
+	mibUploadValuesEnd   = 40
+)
+
+// corruptUploadEntryLength is the length of the MibUploadNext responses corrupted by
+// InjectCorruptUploadEntry, they end right after the class of the uploaded ME
+const corruptUploadEntryLength = 10
+
+// InjectCorruptUploadEntry makes the response to the MibUploadNext with command number entryIndex
+// a truncated frame, to test how the OLT copes with a malformed MIB upload. The corruption applies
+// to the next such MibUploadNext only, so that a MIB upload retried by the OLT succeeds.
+func InjectCorruptUploadEntry(oltId int, intfId uint32, onuId uint32, entryIndex int) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+		return errors.New(errmsg)
+	}
+	numMibUploads := numStaticMibUploads + len(state.mibUploadMes())
+	if entryIndex < 0 || entryIndex >= numMibUploads {
+		return fmt.Errorf("Invalid MIB upload entry %d, the MIB upload has %d entries", entryIndex, numMibUploads)
+	}
+	state.corruptEntries[uint16(entryIndex)] = true
+	return nil
+}
+
+// mibUploadMe is a MibUploadNext response reporting the attributes in mask of an ME instance
+type mibUploadMe struct {
+	OmciMessageIdentifier
+	mask uint16
+}
+
+// mibUploadMes returns the MibUploadNext responses following the static ones: the instances of the MEs
+// flagged MibUpload, in class then instance order, with their readable attributes split in as many responses
+// as needed. It is called with OnuOmciStateMapLock held.
+func (s *OnuOmciState) mibUploadMes() []mibUploadMe {
+	var classes []int
+	for class, def := range MeDefinitions {
+		if def.MibUpload {
+			classes = append(classes, int(class))
+		}
+	}
+	sort.Ints(classes)
+
+	var mes []mibUploadMe
+	for _, c := range classes {
+		class := OmciClass(c)
+		for _, instance := range s.meInstances(class) {
+			id := OmciMessageIdentifier{Class: class, Instance: instance}
+			var mask uint16
+			var size int
+			for i, attrDef := range MeDefinitions[class].Attributes {
+				if attrDef.Access&AttrRead == 0 || attrDef.Table {
+					continue
+				}
+				if size+attrDef.Size > mibUploadValuesEnd-mibUploadValuesStart {
+					mes = append(mes, mibUploadMe{id, mask})
+					mask, size = 0, 0
+				}
+				mask |= attributeMaskBit(i + 1)
+				size += attrDef.Size
+			}
+			if mask != 0 {
+				mes = append(mes, mibUploadMe{id, mask})
+			}
+		}
+	}
+	return mes
+}
+
+// mibUploadEntry returns the MibUploadNext response reporting the attributes in mask of an instance of
+// an ME in MeDefinitions, the attributes have to fit in a single response. It is called with
+// OnuOmciStateMapLock held.
+func (s *OnuOmciState) mibUploadEntry(class OmciClass, instance uint16, mask uint16) []byte {
+	pkt := make([]byte, BaselineFrameLength)
+	pkt[4] = byte(ONUData >> 8)
+	pkt[5] = byte(ONUData & 0xFF)
+	binary.BigEndian.PutUint16(pkt[8:10], uint16(class))
+	binary.BigEndian.PutUint16(pkt[10:12], instance)
+
+	attrs, ok := s.getMe(class, instance)
+	if !ok {
+		return pkt
+	}
+	values, served, _, _ := s.readMeAttributes(class, instance, attrs, mask, mibUploadValuesEnd-mibUploadValuesStart)
+	binary.BigEndian.PutUint16(pkt[12:14], served)
+	copy(pkt[mibUploadValuesStart:], values)
+	return pkt
+}
+
+// checkMibUploadEntry returns an error if the attribute values reported by a MibUploadNext response
+// differ from the ones a Get returns, for the MEs in MeDefinitions. It is called with OnuOmciStateMapLock held.
+func (s *OnuOmciState) checkMibUploadEntry(pkt []byte) error {
+	class := OmciClass(binary.BigEndian.Uint16(pkt[8:10]))
+	instance := binary.BigEndian.Uint16(pkt[10:12])
+	mask := binary.BigEndian.Uint16(pkt[12:14])
+	if _, ok := MeDefinitions[class]; !ok {
+		return nil
+	}
+	attrs, ok := s.getMe(class, instance)
+	if !ok {
+		return fmt.Errorf("MIB upload of %s instance %#04x which does not exist", class.PrettyPrint(), instance)
+	}
 
+	values, served, _, _ := s.readMeAttributes(class, instance, attrs, mask, mibUploadValuesEnd-mibUploadValuesStart)
+	if served != mask {
+		return fmt.Errorf("MIB upload of %s instance %#04x reports attributes %#04x, a Get serves %#04x",
+			class.PrettyPrint(), instance, mask, served)
+	}
+	uploaded := pkt[mibUploadValuesStart : mibUploadValuesStart+len(values)]
+	if !bytes.Equal(uploaded, values) {
+		return fmt.Errorf("MIB upload of %s instance %#04x reports % x, a Get returns % x",
+			class.PrettyPrint(), instance, uploaded, values)
+	}
+	return nil
+}
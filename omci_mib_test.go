@@ -0,0 +1,138 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// startMibUpload starts a MIB upload and returns the number of MibUploadNext it takes
+func (o *testOnu) startMibUpload() int {
+	o.t.Helper()
+	resp := o.send(MibUpload, ONUData, 0, nil)
+	return int(binary.BigEndian.Uint16(resp[8:10]))
+}
+
+func (o *testOnu) mibUploadNext(commandNumber int) ([]byte, error) {
+	request := EncodeRequest(o.nextTxId(), MibUploadNext, ONUData, 0, []byte{byte(commandNumber >> 8), byte(commandNumber)})
+	return OmciSim(0, o.intfId, o.onuId, request)
+}
+
+func TestMibUploadAbortedByMibReset(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.startMibUpload()
+	var entries [][]byte
+	for i := 0; i < 3; i++ {
+		resp, err := onu.mibUploadNext(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, resp[8:])
+	}
+
+	onu.send(MibReset, ONUData, 0, nil)
+	// The aborted upload gets no response
+	if resp, _ := onu.mibUploadNext(3); len(resp) != 0 {
+		t.Errorf("MibUploadNext after a MibReset got %x, expected no response", resp)
+	}
+
+	// A new upload starts over
+	onu.startMibUpload()
+	for i, entry := range entries {
+		resp, err := onu.mibUploadNext(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(resp[8:], entry) {
+			t.Errorf("MibUploadNext %d got %x, expected %x", i, resp[8:], entry)
+		}
+	}
+}
+
+func TestInjectCorruptUploadEntry(t *testing.T) {
+	onu := newTestOnu(t)
+	if err := InjectCorruptUploadEntry(0, onu.intfId, onu.onuId, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		onu.startMibUpload()
+		for i := 0; i < 5; i++ {
+			resp, err := onu.mibUploadNext(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Only the first upload of the third entry is corrupt
+			if corrupt := attempt == 0 && i == 2; corrupt != (len(resp) != BaselineFrameLength) {
+				t.Errorf("Upload %d MibUploadNext %d got a %d byte frame %x", attempt, i, len(resp), resp)
+			}
+		}
+	}
+
+	if err := InjectCorruptUploadEntry(0, onu.intfId, onu.onuId, onu.startMibUpload()); err == nil {
+		t.Error("InjectCorruptUploadEntry past the last entry succeeded")
+	}
+}
+
+func TestNeedsMibReset(t *testing.T) {
+	onu := newTestOnu(t)
+	if NeedsMibReset(0, onu.intfId, onu.onuId) {
+		t.Fatal("A MIB reset is needed right after a MibReset")
+	}
+
+	// A MibUploadNext without a MibUpload means the OLT lost track of the ONU MIB
+	onu.mibUploadNext(0)
+	if !NeedsMibReset(0, onu.intfId, onu.onuId) {
+		t.Error("No MIB reset is needed after a MibUploadNext out of sequence")
+	}
+
+	onu.send(MibReset, ONUData, 0, nil)
+	if NeedsMibReset(0, onu.intfId, onu.onuId) {
+		t.Error("A MIB reset is still needed after a MibReset")
+	}
+}
+
+func TestCheckMibUpload(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.CheckMibUpload = true
+	Config.PortTypes = []byte{0x2f, 0x2f, 0x20}
+	onu := newTestOnu(t)
+
+	// A MibUploadNext failing the check gets no response
+	var portMapping []byte
+	for i, n := 0, onu.startMibUpload(); i < n; i++ {
+		resp, err := onu.mibUploadNext(i)
+		if err != nil || len(resp) == 0 {
+			t.Fatalf("MibUploadNext %d failed the check: %v", i, err)
+		}
+		if OmciClass(binary.BigEndian.Uint16(resp[8:10])) == PortMappingPackage {
+			portMapping = resp
+		}
+	}
+	if portMapping == nil {
+		t.Fatal("The Port Mapping Package is not in the MIB upload")
+	}
+
+	mask := binary.BigEndian.Uint16(portMapping[12:14])
+	values := onu.mustGet(PortMappingPackage, 0, mask)
+	uploaded := portMapping[mibUploadValuesStart:mibUploadValuesEnd]
+	if !bytes.Equal(uploaded, values[:len(uploaded)]) {
+		t.Errorf("MIB upload reports %x, a Get returns %x", uploaded, values[:len(uploaded)])
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// HandlerContext is an OMCI request being processed for an ONU
+type HandlerContext struct {
+	Key           OnuKey
+	TransactionId uint16
+	DeviceId      uint8
+	MsgType       OmciMsgType
+	Class         OmciClass
+	Instance      uint16
+	Content       OmciContent
+	CorrelationId string     // Identifies the log entries of the request, see correlationId
+	Logger        *log.Entry // Logs with the ONU and the correlation id of the request
+}
+
+// correlationId identifies the processing of a request in the logs: the ONU key and the transaction id
+func correlationId(key OnuKey, transactionId uint16) string {
+	return fmt.Sprintf("%d-%d-%d-%04x", key.OltId, key.IntfId, key.OnuId, transactionId)
+}
+
+// HandlerFunc returns the response to an OMCI request, OmciSim fills in its header.
+// No response is sent if it returns an error.
+type HandlerFunc func(ctx *HandlerContext) ([]byte, error)
+
+// Middleware wraps the processing of the OMCI requests, it may call next or answer the request itself
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// defaultMiddlewares are the simulator behaviors applied to every request
+var defaultMiddlewares = []Middleware{
+	logRequest,
+	trackMibDataSync,
+	rejectLockedProvisioning,
+	rejectBaselineTableSet,
+}
+
+var middlewares = append([]Middleware{}, defaultMiddlewares...)
+var middlewaresLock = sync.RWMutex{}
+
+// requestPipeline is handleRequest run through the middlewares, built again when they change
+var requestPipeline = pipeline(middlewares, handleRequest)
+
+// Use adds middlewares to the request processing pipeline, after the ones already added: the first
+// middleware of the pipeline sees the request first and the response last
+func Use(mw ...Middleware) {
+	middlewaresLock.Lock()
+	defer middlewaresLock.Unlock()
+	middlewares = append(middlewares, mw...)
+	requestPipeline = pipeline(middlewares, handleRequest)
+}
+
+// ResetMiddlewares removes the middlewares added with Use
+func ResetMiddlewares() {
+	middlewaresLock.Lock()
+	defer middlewaresLock.Unlock()
+	middlewares = append([]Middleware{}, defaultMiddlewares...)
+	requestPipeline = pipeline(middlewares, handleRequest)
+}
+
+// pipeline returns handler run through the middlewares
+func pipeline(middlewares []Middleware, handler HandlerFunc) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// requestHandler returns the handler of the requests, run through the middlewares
+func requestHandler() HandlerFunc {
+	middlewaresLock.RLock()
+	defer middlewaresLock.RUnlock()
+	return requestPipeline
+}
+
+// resultOffset returns the offset of the result reason in the responses of a message set: it follows
+// the contents length in the extended frames
+func resultOffset(deviceId uint8) int {
+	if deviceId == ExtendedDeviceId {
+		return ExtendedHeaderLength
+	}
+	return messageHeaderLength
+}
+
+// handleRequest runs the request handler of the message type
+func handleRequest(ctx *HandlerContext) ([]byte, error) {
+	if ctx.MsgType == Get && ctx.DeviceId == ExtendedDeviceId {
+		return extendedGet(ctx)
+	}
+	return Handlers[ctx.MsgType](ctx)
+}
+
+func logRequest(next HandlerFunc) HandlerFunc {
+	return func(ctx *HandlerContext) ([]byte, error) {
+		ctx.Logger.WithFields(log.Fields{
+			"TransactionId": ctx.TransactionId,
+			"MessageType":   ctx.MsgType.PrettyPrint(),
+			"MeClass":       ctx.Class,
+			"MeInstance":    ctx.Instance,
+			"omciMsg":       fmt.Sprintf("%x", ctx.Content),
+		}).Tracef("Processing OMCI packet")
+
+		resp, err := next(ctx)
+		if err != nil {
+			ctx.Logger.WithFields(log.Fields{
+				"msgType": ctx.MsgType,
+			}).Errorf("Unable to send a successful response, error: %s", err)
+		}
+		return resp, err
+	}
+}
+
+// rejectLockedProvisioning answers device busy to the Create, Set and Delete of the ONUs
+// whose provisioning is locked, see SetProvisioningLock
+func rejectLockedProvisioning(next HandlerFunc) HandlerFunc {
+	return func(ctx *HandlerContext) ([]byte, error) {
+		if !isProvisioningLocked(ctx.Key) || (ctx.MsgType != Create && ctx.MsgType != Set && ctx.MsgType != Delete) {
+			return next(ctx)
+		}
+
+		ctx.Logger.WithFields(log.Fields{
+			"msgType": ctx.MsgType.PrettyPrint(),
+		}).Warnf("Rejecting omci msg, provisioning is locked")
+		resp := make([]byte, BaselineFrameLength)
+		resp[8] = byte(DeviceBusy)
+		return resp, nil
+	}
+}
+
+// rejectBaselineTableSet answers not supported to the baseline Sets writing a table attribute,
+// see Config.RejectBaselineTableSet
+func rejectBaselineTableSet(next HandlerFunc) HandlerFunc {
+	return func(ctx *HandlerContext) ([]byte, error) {
+		if !Config.RejectBaselineTableSet || ctx.MsgType != Set || ctx.DeviceId != BaselineDeviceId {
+			return next(ctx)
+		}
+		tables := tableAttributesMask(ctx.Class, ctx.Content)
+		if tables == 0 {
+			return next(ctx)
+		}
+
+		ctx.Logger.WithFields(log.Fields{
+			"MeClass": ctx.Class.PrettyPrint(),
+		}).Warnf("Rejecting baseline Set on a table attribute, the extended message set is expected")
+		// The optional-attribute mask tells the OLT which attributes it should write otherwise
+		resp := make([]byte, BaselineFrameLength)
+		resp[8] = byte(NotSupported)
+		resp[9] = byte(tables >> 8)
+		resp[10] = byte(tables & 0xFF)
+		return resp, nil
+	}
+}
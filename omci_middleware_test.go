@@ -0,0 +1,134 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	defer ResetMiddlewares()
+	onu := newTestOnu(t)
+
+	// Answer the Gets of the ONU with a canned device busy response
+	Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *HandlerContext) ([]byte, error) {
+			if ctx.Key.IntfId != onu.intfId || ctx.MsgType != Get {
+				return next(ctx)
+			}
+			resp := make([]byte, BaselineFrameLength)
+			resp[8] = byte(DeviceBusy)
+			return resp, nil
+		}
+	})
+
+	request := EncodeRequest(onu.nextTxId(), Get, ONUG, 0, []byte{0x80, 0x00})
+	resp := onu.sendFrame(request)
+	if result := onu.result(resp); result != DeviceBusy {
+		t.Errorf("Get got result %d, expected the canned %d", result, DeviceBusy)
+	}
+	// The header is still filled in by OmciSim
+	if txId := binary.BigEndian.Uint16(resp[0:2]); txId != onu.txId || resp[2]&0x1f != byte(Get) || !reflect.DeepEqual(resp[4:8], request[4:8]) {
+		t.Errorf("Response header is %x, expected the one of the request %x", resp[0:8], request[0:8])
+	}
+	// The other requests reach the handlers
+	if result, _ := onu.set(PPTPEthernetUNI, 257, 0x0800, 0x00); result != Success {
+		t.Errorf("Set got result %d, expected %d", result, Success)
+	}
+
+	ResetMiddlewares()
+	if result, _ := onu.get(ONUG, 0, 0x8000); result != Success {
+		t.Errorf("Get after ResetMiddlewares got result %d, expected %d", result, Success)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	defer ResetMiddlewares()
+	onu := newTestOnu(t)
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx *HandlerContext) ([]byte, error) {
+				calls = append(calls, name+" request")
+				resp, err := next(ctx)
+				calls = append(calls, name+" response")
+				return resp, err
+			}
+		}
+	}
+	Use(record("first"), record("second"))
+
+	onu.mustGet(ONUG, 0, 0x8000)
+	expected := []string{"first request", "second request", "second response", "first response"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Middlewares were called as %v, expected %v", calls, expected)
+	}
+}
+
+func TestCorrelationId(t *testing.T) {
+	level := log.GetLevel()
+	log.SetLevel(log.TraceLevel)
+	defer log.SetLevel(level)
+	hook := test.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+	onu := newTestOnu(t)
+
+	onu.sendFrame(EncodeRequest(0x1234, Get, ONUG, 0, []byte{byte(VendorID >> 8), 0x00}))
+	expected := correlationId(OnuKey{0, onu.intfId, onu.onuId}, 0x1234)
+	// The request is logged when it is parsed, by its handler and when it is answered
+	corrIds := map[string]interface{}{}
+	for _, entry := range hook.AllEntries() {
+		if entry.Data["IntfId"] == onu.intfId {
+			corrIds[entry.Message] = entry.Data["CorrelationId"]
+		}
+	}
+	for _, message := range []string{"Processing OMCI packet", "Omci Get", "OMCI-SIM Response"} {
+		if corrId, ok := corrIds[message]; !ok || corrId != expected {
+			t.Errorf("%q is logged with the correlation id %v, expected %s", message, corrId, expected)
+		}
+	}
+}
+
+func TestMiddlewareShortResponse(t *testing.T) {
+	defer ResetMiddlewares()
+	onu := newTestOnu(t)
+
+	built := 0
+	Use(func(next HandlerFunc) HandlerFunc {
+		built++
+		return func(ctx *HandlerContext) ([]byte, error) {
+			return nil, nil
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		request := EncodeRequest(onu.nextTxId(), Get, ONUG, 0, []byte{0x80, 0x00})
+		if resp, err := OmciSim(0, onu.intfId, onu.onuId, request); err == nil {
+			t.Errorf("An empty response got no error, response %x", resp)
+		}
+	}
+	// The pipeline is built when the middlewares change, not for every request
+	if built != 1 {
+		t.Errorf("Middleware wrapped %d times, expected once", built)
+	}
+}
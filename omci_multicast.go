@@ -0,0 +1,152 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+)
+
+// Multicast Operations Profile attribute numbers
+const (
+	McastOpsIgmpVersion = iota + 1
+	McastOpsIgmpFunction
+	McastOpsImmediateLeave
+	McastOpsUpstreamIgmpTci
+	McastOpsUpstreamIgmpTagControl
+	McastOpsUpstreamIgmpRate
+	McastOpsDynamicAccessControlListTable
+	McastOpsStaticAccessControlListTable
+	McastOpsLostGroupsListTable
+	McastOpsRobustness
+	McastOpsQuerierIpAddress
+	McastOpsQueryInterval
+	McastOpsQueryMaxResponseTime
+	McastOpsLastMemberQueryInterval
+	McastOpsUnauthorizedJoinRequestBehaviour
+	McastOpsDownstreamIgmpAndMulticastTci
+)
+
+// Multicast Subscriber Config Info attribute numbers
+const (
+	McastSubscriberMeType = iota + 1
+	McastSubscriberMulticastOperationsProfilePointer
+	McastSubscriberMaxSimultaneousGroups
+	McastSubscriberMaxMulticastBandwidth
+	McastSubscriberBandwidthEnforcement
+	McastSubscriberMulticastServicePackageTable
+	McastSubscriberAllowedPreviewGroupsTable
+)
+
+// The first 2 bytes of an access control list entry are its table control: the set control in the
+// 2 most significant bits, then the row part and the row key identifying the entry
+const (
+	mcastAclTableEntrySize = 24
+	mcastAclRowPart        = 0x7000
+	mcastAclRowKey         = 0x03FF
+)
+
+// Set controls of the access control list entries
+const (
+	mcastAclWrite = iota + 1
+	mcastAclDelete
+	mcastAclClearAll
+)
+
+func init() {
+	MeDefinitions[MulticastOperationsProfile] = &MeDefinition{
+		Name: "MulticastOperationsProfile",
+		Attributes: []AttributeDefinition{
+			{Name: "IgmpVersion", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "IgmpFunction", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "ImmediateLeave", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "UpstreamIgmpTci", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "UpstreamIgmpTagControl", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "UpstreamIgmpRate", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "DynamicAccessControlListTable", Size: mcastAclTableEntrySize, Access: AttrRead | AttrWrite,
+				Table: true, SetEntry: setMcastAclTableEntry},
+			{Name: "StaticAccessControlListTable", Size: mcastAclTableEntrySize, Access: AttrRead | AttrWrite,
+				Table: true, SetEntry: setMcastAclTableEntry},
+			{Name: "LostGroupsListTable", Size: 10, Access: AttrRead, Table: true},
+			{Name: "Robustness", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "QuerierIpAddress", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "QueryInterval", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "QueryMaxResponseTime", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "LastMemberQueryInterval", Size: 4, Access: AttrRead | AttrWrite},
+			{Name: "UnauthorizedJoinRequestBehaviour", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "DownstreamIgmpAndMulticastTci", Size: 3, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+	}
+
+	MeDefinitions[MulticastSubscriberConfigInfo] = &MeDefinition{
+		Name: "MulticastSubscriberConfigInfo",
+		Attributes: []AttributeDefinition{
+			{Name: "MeType", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MulticastOperationsProfilePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MaxSimultaneousGroups", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MaxMulticastBandwidth", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "BandwidthEnforcement", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MulticastServicePackageTable", Size: 20, Access: AttrRead | AttrWrite, Table: true},
+			{Name: "AllowedPreviewGroupsTable", Size: 22, Access: AttrRead | AttrWrite, Table: true},
+		},
+		Validate: validateMulticastSubscriberConfigInfo,
+	}
+}
+
+func validateMulticastSubscriberConfigInfo(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	var failed uint16
+
+	// The ME is associated with a MAC Bridge Port Configuration Data (0) or an 802.1p Mapper Service Profile (1)
+	if attrs[McastSubscriberMeType][0] > 1 {
+		failed |= attributeMaskBit(McastSubscriberMeType)
+	}
+
+	profile := attrs.uint16(McastSubscriberMulticastOperationsProfilePointer)
+	if _, ok := state.getMe(MulticastOperationsProfile, profile); !isNullPointer(profile) && !ok {
+		failed |= attributeMaskBit(McastSubscriberMulticastOperationsProfilePointer)
+	}
+
+	return failed
+}
+
+// setMcastAclTableEntry writes, deletes or clears all the entries of an access control list table, as
+// told by the set control of the entry. A delete removes all the parts of the row
+func setMcastAclTableEntry(table []byte, entry []byte) ([]byte, bool) {
+	control := binary.BigEndian.Uint16(entry)
+
+	switch control >> 14 {
+	case mcastAclWrite:
+		for pos := 0; pos+mcastAclTableEntrySize <= len(table); pos += mcastAclTableEntrySize {
+			if binary.BigEndian.Uint16(table[pos:])&(mcastAclRowPart|mcastAclRowKey) == control&(mcastAclRowPart|mcastAclRowKey) {
+				copy(table[pos:], entry)
+				return table, true
+			}
+		}
+		return append(table, entry...), true
+	case mcastAclDelete:
+		kept := table[:0]
+		for pos := 0; pos+mcastAclTableEntrySize <= len(table); pos += mcastAclTableEntrySize {
+			if binary.BigEndian.Uint16(table[pos:])&mcastAclRowKey != control&mcastAclRowKey {
+				kept = append(kept, table[pos:pos+mcastAclTableEntrySize]...)
+			}
+		}
+		return kept, true
+	case mcastAclClearAll:
+		return table[:0], true
+	}
+	// The set control 0 is reserved
+	return table, false
+}
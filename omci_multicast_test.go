@@ -0,0 +1,92 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestMulticastSubscriberMaxGroups(t *testing.T) {
+	onu := newTestOnu(t)
+	// IGMPv3 profile
+	onu.mustCreate(MulticastOperationsProfile, 1, map[int][]byte{McastOpsIgmpVersion: {0x03}})
+	onu.mustCreate(MulticastSubscriberConfigInfo, 0x0101, map[int][]byte{
+		McastSubscriberMulticastOperationsProfilePointer: {0x00, 0x01},
+		McastSubscriberMaxSimultaneousGroups:             {0x00, 0x10},
+	})
+
+	values := onu.mustGet(MulticastSubscriberConfigInfo, 0x0101, attributeMaskBit(McastSubscriberMaxSimultaneousGroups))
+	if groups := binary.BigEndian.Uint16(values); groups != 16 {
+		t.Errorf("Max simultaneous groups is %d, expected 16", groups)
+	}
+
+	// The profile must exist
+	result := onu.create(MulticastSubscriberConfigInfo, 0x0102, map[int][]byte{
+		McastSubscriberMulticastOperationsProfilePointer: {0x00, 0x02},
+	})
+	if result != ParameterError {
+		t.Errorf("Create pointing to a missing profile got result %d, expected %d", result, ParameterError)
+	}
+}
+
+func TestMulticastAccessControlList(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(MulticastOperationsProfile, 1, map[int][]byte{McastOpsIgmpVersion: {0x03}})
+	mask := attributeMaskBit(McastOpsDynamicAccessControlListTable)
+	aclEntry := func(control uint16, gemPort byte) []byte {
+		entry := make([]byte, mcastAclTableEntrySize)
+		binary.BigEndian.PutUint16(entry, control)
+		entry[3] = gemPort
+		return entry
+	}
+	setEntry := func(entry []byte) {
+		if result, _ := onu.set(MulticastOperationsProfile, 1, mask, entry...); result != Success {
+			t.Fatalf("Set of the ACL entry %x got result %d", entry[:2], result)
+		}
+	}
+
+	// Two rows, the first one of two parts, the second one written twice
+	setEntry(aclEntry(0x4001, 1))
+	setEntry(aclEntry(0x5001, 1))
+	setEntry(aclEntry(0x4002, 2))
+	setEntry(aclEntry(0x4002, 3))
+	table := onu.readTable(MulticastOperationsProfile, 1, McastOpsDynamicAccessControlListTable)
+	if len(table) != 3*mcastAclTableEntrySize {
+		t.Fatalf("ACL table of %d bytes, expected 3 entries", len(table))
+	}
+	if last := table[2*mcastAclTableEntrySize:]; last[3] != 3 {
+		t.Errorf("Rewritten ACL entry has GEM port %d, expected 3", last[3])
+	}
+
+	// Deleting a row removes all its parts
+	setEntry(aclEntry(0x8001, 0))
+	table = onu.readTable(MulticastOperationsProfile, 1, McastOpsDynamicAccessControlListTable)
+	if len(table) != mcastAclTableEntrySize || binary.BigEndian.Uint16(table)&mcastAclRowKey != 2 {
+		t.Fatalf("ACL table is %x after deleting the row 1, expected the row 2 alone", table)
+	}
+
+	setEntry(aclEntry(0xC000, 0))
+	if table = onu.readTable(MulticastOperationsProfile, 1, McastOpsDynamicAccessControlListTable); len(table) != 0 {
+		t.Errorf("ACL table is %x after a clear all, expected it empty", table)
+	}
+
+	// The set control 0 is reserved
+	if result, _ := onu.set(MulticastOperationsProfile, 1, mask, aclEntry(0x0001, 1)...); result != ParameterError {
+		t.Errorf("Set of an ACL entry with a reserved set control got result %d, expected %d", result, ParameterError)
+	}
+}
@@ -0,0 +1,284 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AlarmBitmapLength is the size of the alarm bitmap carried by an AlarmNotification
+const AlarmBitmapLength = 28
+
+type alarmBitmap [AlarmBitmapLength]byte
+
+func newAlarmNotification(class OmciClass, instance uint16, bitmap alarmBitmap, sequenceNumber uint8) []byte {
+	pkt := []byte{
+		0x00, 0x00, 0x10, 0x0a, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	pkt[0] = byte(AutonomousTransactionId >> 8)
+	pkt[1] = byte(AutonomousTransactionId & 0xFF)
+	pkt[4] = byte(class >> 8)
+	pkt[5] = byte(class & 0xFF)
+	pkt[6] = byte(instance >> 8)
+	pkt[7] = byte(instance & 0xFF)
+	copy(pkt[8:8+AlarmBitmapLength], bitmap[:])
+	pkt[39] = sequenceNumber // Alarm sequence number is the last byte of the message contents
+
+	return pkt
+}
+
+// newAttributeValueChange returns an AttributeValueChange reporting the value of a single attribute
+func newAttributeValueChange(class OmciClass, instance uint16, attribute int, value []byte) []byte {
+	pkt := make([]byte, BaselineFrameLength)
+	pkt[0] = byte(AutonomousTransactionId >> 8)
+	pkt[1] = byte(AutonomousTransactionId & 0xFF)
+	pkt[2] = byte(AttributeValueChange)
+	pkt[3] = BaselineDeviceId
+	pkt[4] = byte(class >> 8)
+	pkt[5] = byte(class & 0xFF)
+	pkt[6] = byte(instance >> 8)
+	pkt[7] = byte(instance & 0xFF)
+	mask := attributeMaskBit(attribute)
+	pkt[8] = byte(mask >> 8)
+	pkt[9] = byte(mask & 0xFF)
+	copy(pkt[10:], value)
+
+	return pkt
+}
+
+// sendAttributeValueChange sends an AttributeValueChange for an attribute changed by the ONU on the OMCI Sim channel
+func sendAttributeValueChange(key OnuKey, class OmciClass, instance uint16, attribute int, value []byte) {
+	omciCh <- attributeValueChange(key, class, instance, attribute, value)
+}
+
+// attributeValueChange returns the AttributeValueChange message for an attribute changed by the ONU
+func attributeValueChange(key OnuKey, class OmciClass, instance uint16, attribute int, value []byte) OmciChMessage {
+	log.WithFields(log.Fields{
+		"IntfId":    key.IntfId,
+		"OnuId":     key.OnuId,
+		"MeClass":   class.PrettyPrint(),
+		"Instance":  instance,
+		"Attribute": attribute,
+	}).Infof("Send %s on OMCI Sim channel", AttributeValueChanged)
+
+	return OmciChMessage{
+		Type: AttributeValueChanged,
+		Data: OmciChMessageData{
+			OnuId:  key.OnuId,
+			IntfId: key.IntfId,
+		},
+		Packet: newAttributeValueChange(class, instance, attribute, value),
+	}
+}
+
+// pendingNotifications are the notifications queued while OnuOmciStateMap is locked, see unlockAndNotify
+var pendingNotifications []OmciChMessage
+
+// queueNotification queues a notification to be sent on the OMCI Sim channel once OnuOmciStateMap is
+// unlocked, the caller holds the write lock and releases it with unlockAndNotify
+func queueNotification(msg OmciChMessage) {
+	pendingNotifications = append(pendingNotifications, msg)
+}
+
+// unlockAndNotify unlocks OnuOmciStateMap, then sends the notifications queued while it was locked: a
+// slow reader of the OMCI Sim channel must not block the requests of all the ONUs
+func unlockAndNotify() {
+	msgs := pendingNotifications
+	pendingNotifications = nil
+	OnuOmciStateMapLock.Unlock()
+
+	for _, msg := range msgs {
+		omciCh <- msg
+	}
+}
+
+// setAlarm raises or clears an alarm of an ME and, if that changes the ME alarm bitmap,
+// queues the resulting AlarmNotification, see queueNotification
+func (s *OnuOmciState) setAlarm(key OnuKey, class OmciClass, instance uint16, alarm uint, raised bool) {
+	id := OmciMessageIdentifier{Class: class, Instance: instance}
+	bitmap := s.alarms[id]
+
+	// Alarm 0 is the most significant bit of the first byte
+	mask := byte(0x80 >> (alarm % 8))
+	if ((bitmap[alarm/8] & mask) != 0) == raised {
+		return
+	}
+
+	msgType := AlarmCleared
+	if raised {
+		bitmap[alarm/8] |= mask
+		msgType = AlarmRaised
+	} else {
+		bitmap[alarm/8] &^= mask
+	}
+	s.alarms[id] = bitmap
+
+	// The alarm sequence number wraps from 255 to 1, 0 is reserved
+	s.alarmSeqNumber++
+	if s.alarmSeqNumber == 0 {
+		s.alarmSeqNumber = 1
+	}
+
+	log.WithFields(log.Fields{
+		"IntfId":   key.IntfId,
+		"OnuId":    key.OnuId,
+		"MeClass":  class.PrettyPrint(),
+		"Instance": instance,
+		"Alarm":    alarm,
+	}).Infof("Send %s on OMCI Sim channel", msgType)
+
+	queueNotification(OmciChMessage{
+		Type: msgType,
+		Data: OmciChMessageData{
+			OnuId:  key.OnuId,
+			IntfId: key.IntfId,
+		},
+		Packet: newAlarmNotification(class, instance, bitmap, s.alarmSeqNumber),
+	})
+}
+
+// ScheduledNotification is an autonomous notification sent Delay after the notification script is started
+type ScheduledNotification struct {
+	Delay time.Duration
+	Key   OnuKey
+	Type  ChMessageType
+	// Class, Instance and Alarm identify the alarm of an AlarmRaised or AlarmCleared notification
+	Class    OmciClass
+	Instance uint16
+	Alarm    uint
+	// Packet is sent for any other type of notification, with the autonomous transaction id
+	Packet []byte
+}
+
+var notificationScript []ScheduledNotification
+var notificationScriptStop chan struct{}
+var notificationScriptDone sync.WaitGroup
+var notificationScriptLock = sync.Mutex{}
+
+// LoadNotificationScript replaces the notifications sent by StartNotificationScript
+func LoadNotificationScript(script []ScheduledNotification) {
+	notificationScriptLock.Lock()
+	defer notificationScriptLock.Unlock()
+
+	notificationScript = make([]ScheduledNotification, len(script))
+	copy(notificationScript, script)
+	sort.SliceStable(notificationScript, func(i, j int) bool {
+		return notificationScript[i].Delay < notificationScript[j].Delay
+	})
+}
+
+// StartNotificationScript sends the loaded notifications on the OMCI Sim channel, each one at its
+// scheduled delay, until the script is over or Shutdown is called
+func StartNotificationScript() error {
+	notificationScriptLock.Lock()
+	defer notificationScriptLock.Unlock()
+
+	if notificationScriptStop != nil {
+		return errors.New("Notification script already running")
+	}
+	stop := make(chan struct{})
+	notificationScriptStop = stop
+
+	notificationScriptDone.Add(1)
+	go func(script []ScheduledNotification) {
+		defer notificationScriptDone.Done()
+		start := time.Now()
+		for _, n := range script {
+			timer := time.NewTimer(time.Until(start.Add(n.Delay)))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+				sendScheduledNotification(n)
+			}
+		}
+
+		// The script is over, it can be started again
+		notificationScriptLock.Lock()
+		if notificationScriptStop == stop {
+			notificationScriptStop = nil
+		}
+		notificationScriptLock.Unlock()
+	}(notificationScript)
+
+	return nil
+}
+
+// Shutdown stops the notification script, if running
+func Shutdown() {
+	notificationScriptLock.Lock()
+	if notificationScriptStop != nil {
+		close(notificationScriptStop)
+		notificationScriptStop = nil
+	}
+	notificationScriptLock.Unlock()
+
+	notificationScriptDone.Wait()
+}
+
+func sendScheduledNotification(n ScheduledNotification) {
+	if n.Type != AlarmRaised && n.Type != AlarmCleared {
+		pkt := append([]byte{}, n.Packet...)
+		if len(pkt) >= 2 {
+			pkt[0] = byte(AutonomousTransactionId >> 8)
+			pkt[1] = byte(AutonomousTransactionId & 0xFF)
+		}
+		omciCh <- OmciChMessage{
+			Type: n.Type,
+			Data: OmciChMessageData{
+				OnuId:  n.Key.OnuId,
+				IntfId: n.Key.IntfId,
+			},
+			Packet: pkt,
+		}
+		return
+	}
+
+	OnuOmciStateMapLock.Lock()
+	defer unlockAndNotify()
+	state, ok := OnuOmciStateMap[n.Key]
+	if !ok {
+		log.WithFields(log.Fields{
+			"IntfId": n.Key.IntfId,
+			"OnuId":  n.Key.OnuId,
+		}).Warnf("Dropping scheduled %s, ONU not found", n.Type)
+		return
+	}
+	if _, modeled := MeDefinitions[n.Class]; modeled {
+		if _, ok := state.getMe(n.Class, n.Instance); !ok {
+			log.WithFields(log.Fields{
+				"IntfId":   n.Key.IntfId,
+				"OnuId":    n.Key.OnuId,
+				"MeClass":  n.Class.PrettyPrint(),
+				"Instance": n.Instance,
+			}).Warnf("Dropping scheduled %s, ME instance not found", n.Type)
+			return
+		}
+	}
+	state.setAlarm(n.Key, n.Class, n.Instance, n.Alarm, n.Type == AlarmRaised)
+}
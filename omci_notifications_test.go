@@ -0,0 +1,128 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestNotificationScript(t *testing.T) {
+	onu := newTestOnu(t)
+	key := OnuKey{0, onu.intfId, onu.onuId}
+	avc := newAttributeValueChange(ONUG, 0, 8, []byte{0x01})
+	avc[0], avc[1] = 0x12, 0x34
+
+	// The script is ordered by delay
+	LoadNotificationScript([]ScheduledNotification{
+		{Delay: 20 * time.Millisecond, Key: key, Type: AttributeValueChanged, Packet: avc},
+		{Delay: 10 * time.Millisecond, Key: key, Type: AlarmRaised, Class: ONUG, Alarm: OnuGBatteryLowAlarm},
+	})
+	defer LoadNotificationScript(nil)
+	if err := StartNotificationScript(); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown()
+
+	msg := onu.notification()
+	if msg.Type != AlarmRaised || !alarmRaised(msg.Packet, OnuGBatteryLowAlarm) {
+		t.Fatalf("Got %s %x, expected the battery-low alarm", msg.Type, msg.Packet)
+	}
+	msg = onu.notification()
+	if msg.Type != AttributeValueChanged {
+		t.Fatalf("Got %s, expected %s", msg.Type, AttributeValueChanged)
+	}
+	if msg.Packet[0] != 0 || msg.Packet[1] != 0 {
+		t.Errorf("Got transaction id %#02x%02x, expected the autonomous one", msg.Packet[0], msg.Packet[1])
+	}
+}
+
+func TestNotificationScriptRestart(t *testing.T) {
+	onu := newTestOnu(t)
+	key := OnuKey{0, onu.intfId, onu.onuId}
+	LoadNotificationScript([]ScheduledNotification{
+		{Key: key, Type: AttributeValueChanged, Packet: newAttributeValueChange(ONUG, 0, 8, []byte{0x01})},
+	})
+	defer LoadNotificationScript(nil)
+
+	// A script over can be started again without Shutdown
+	for i := 0; i < 2; i++ {
+		// The script is over once its last notification is sent
+		deadline := time.Now().Add(time.Second)
+		err := StartNotificationScript()
+		for ; err != nil && time.Now().Before(deadline); err = StartNotificationScript() {
+			time.Sleep(time.Millisecond)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg := onu.notification(); msg.Type != AttributeValueChanged {
+			t.Fatalf("Got %s, expected %s", msg.Type, AttributeValueChanged)
+		}
+	}
+	Shutdown()
+}
+
+func TestNotificationScriptShutdown(t *testing.T) {
+	onu := newTestOnu(t)
+	key := OnuKey{0, onu.intfId, onu.onuId}
+	LoadNotificationScript([]ScheduledNotification{
+		{Delay: 50 * time.Millisecond, Key: key, Type: AlarmRaised, Class: ONUG, Alarm: OnuGBatteryLowAlarm},
+	})
+	defer LoadNotificationScript(nil)
+	if err := StartNotificationScript(); err != nil {
+		t.Fatal(err)
+	}
+	if err := StartNotificationScript(); err == nil {
+		t.Error("Expected an error starting a script already running")
+	}
+
+	Shutdown()
+	time.Sleep(100 * time.Millisecond)
+	onu.expectNoNotification()
+}
+
+func TestAutonomousTransactionId(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	onu := newTestOnu(t)
+
+	resp := onu.send(Get, ONUG, 0, []byte{0x80, 0x00})
+	if txId := binary.BigEndian.Uint16(resp[0:2]); txId != onu.txId || txId == AutonomousTransactionId {
+		t.Errorf("Response transaction id is %d, expected the one of the request %d", txId, onu.txId)
+	}
+
+	if err := SimulateAniLos(0, onu.intfId, onu.onuId, true); err != nil {
+		t.Fatal(err)
+	}
+	if msg := onu.notification(); binary.BigEndian.Uint16(msg.Packet[0:2]) != AutonomousTransactionId {
+		t.Errorf("%s transaction id is %d, expected %d", msg.Type, binary.BigEndian.Uint16(msg.Packet[0:2]), AutonomousTransactionId)
+	}
+
+	// A request with the autonomous transaction id is answered, unless told otherwise
+	request := EncodeRequest(AutonomousTransactionId, Get, ONUG, 0, []byte{0x80, 0x00})
+	if result := onu.result(onu.sendFrame(request)); result != Success {
+		t.Errorf("Request with transaction id 0 got result %d, expected %d", result, Success)
+	}
+	Config.DropZeroTransactionId = true
+	if resp, err := OmciSim(0, onu.intfId, onu.onuId, request); err == nil {
+		t.Errorf("Request with transaction id 0 got %x, expected an error", resp)
+	}
+	if err := ValidateFrame(request); err == nil {
+		t.Error("ValidateFrame of a request with transaction id 0 succeeded")
+	}
+}
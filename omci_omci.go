@@ -0,0 +1,61 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// OMCI attribute numbers
+const (
+	OmciMeTypeTable = iota + 1
+	OmciMessageTypeTable
+)
+
+// The OMCI ME lists the ME classes in MeDefinitions and the message types in Handlers, the OMCC version
+// is reported by the ONU2-G
+func init() {
+	MeDefinitions[OMCI] = &MeDefinition{
+		Name: "Omci",
+		Attributes: []AttributeDefinition{
+			{Name: "MeTypeTable", Size: 2, Access: AttrRead, Table: true, Value: omciMeTypes},
+			{Name: "MessageTypeTable", Size: 1, Access: AttrRead, Table: true, Value: omciMessageTypes},
+		},
+		Instances: func() []uint16 {
+			return []uint16{0}
+		},
+	}
+}
+
+func omciMeTypes(state *OnuOmciState, instance uint16) []byte {
+	classes := managedEntityClasses()
+	table := make([]byte, 2*len(classes))
+	for i, class := range classes {
+		binary.BigEndian.PutUint16(table[2*i:], class)
+	}
+	return table
+}
+
+func omciMessageTypes(state *OnuOmciState, instance uint16) []byte {
+	table := make([]byte, 0, len(Handlers))
+	for msgType := range Handlers {
+		table = append(table, byte(msgType))
+	}
+	sort.Slice(table, func(i, j int) bool { return table[i] < table[j] })
+	return table
+}
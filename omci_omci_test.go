@@ -0,0 +1,50 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestOmciMessageTypeTable(t *testing.T) {
+	onu := newTestOnu(t)
+
+	table := onu.readTable(OMCI, 0, OmciMessageTypeTable)
+	if bytes.IndexByte(table, byte(Get)) < 0 {
+		t.Errorf("Message type table %x does not list Get", table)
+	}
+	if len(table) != len(Handlers) {
+		t.Errorf("Message type table lists %d message types, expected %d", len(table), len(Handlers))
+	}
+}
+
+func TestOmciMeTypeTable(t *testing.T) {
+	onu := newTestOnu(t)
+
+	table := onu.readTable(OMCI, 0, OmciMeTypeTable)
+	found := false
+	for i := 0; i+2 <= len(table); i += 2 {
+		if OmciClass(binary.BigEndian.Uint16(table[i:])) == OMCI {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ME type table %x does not list the OMCI ME", table)
+	}
+}
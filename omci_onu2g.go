@@ -40,7 +40,7 @@ const (
 	PriorityQueueScaleFactor    Onu2GAttributes = 0x0004
 )
 
-type Onu2GAttributeHandler func(*uint, []byte) ([]byte, error)
+type Onu2GAttributeHandler func(*uint, []byte, OnuKey) ([]byte, error)
 
 var Onu2GAttributeHandlers = map[Onu2GAttributes]Onu2GAttributeHandler{
 	EquipmentID:                 GetEquipmentID,
@@ -59,7 +59,7 @@ var Onu2GAttributeHandlers = map[Onu2GAttributes]Onu2GAttributeHandler{
 	PriorityQueueScaleFactor:    GetPriorityQueueScaleFactor,
 }
 
-func GetOnu2GAttributes(pos *uint, pkt []byte, content OmciContent) ([]byte, error) {
+func GetOnu2GAttributes(pos *uint, pkt []byte, content OmciContent, key OnuKey) ([]byte, error) {
 	AttributesMask := getAttributeMask(content)
 
 	for index := uint(16); index >= 1; index-- {
@@ -67,7 +67,13 @@ func GetOnu2GAttributes(pos *uint, pkt []byte, content OmciContent) ([]byte, err
 		reqAttribute := Attribute & AttributesMask
 
 		if reqAttribute != 0 {
-			pkt, _ = Onu2GAttributeHandlers[Onu2GAttributes(reqAttribute)](pos, pkt)
+			handler, ok := Onu2GAttributeHandlers[Onu2GAttributes(reqAttribute)]
+			if !ok {
+				// Attributes without a handler are left out of the response
+				AttributesMask &^= reqAttribute
+				continue
+			}
+			pkt, _ = handler(pos, pkt, key)
 		}
 	}
 
@@ -79,24 +85,20 @@ func GetOnu2GAttributes(pos *uint, pkt []byte, content OmciContent) ([]byte, err
 
 }
 
-func GetEquipmentID(pos *uint, pkt []byte) ([]byte, error) {
+func GetEquipmentID(pos *uint, pkt []byte, key OnuKey) ([]byte, error) {
 	// 20 bytes
-	equipid := []byte("12345123451234512345")
-	for _, ch := range equipid {
-		pkt[*pos] = ch
-		*pos++
-	}
+	putConfigString(pos, pkt, getOnuConfig(key).EquipmentId, equipmentIdLength, []byte("12345123451234512345"))
 	return pkt, nil
 }
 
-func GetOmccVersion(pos *uint, pkt []byte) ([]byte, error) {
+func GetOmccVersion(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 1 bytes
 	pkt[*pos] = 0xB4
 	*pos++
 	return pkt, nil
 }
 
-func GetVendorProductCode(pos *uint, pkt []byte) ([]byte, error) {
+func GetVendorProductCode(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 2 bytes
 	prodcode := []byte{0x00, 0x00}
 	for _, ch := range prodcode {
@@ -106,21 +108,21 @@ func GetVendorProductCode(pos *uint, pkt []byte) ([]byte, error) {
 	return pkt, nil
 }
 
-func GetSecurityCapability(pos *uint, pkt []byte) ([]byte, error) {
+func GetSecurityCapability(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 1 byte
 	pkt[*pos] = 0x01
 	*pos++
 	return pkt, nil
 }
 
-func GetSecurityMode(pos *uint, pkt []byte) ([]byte, error) {
+func GetSecurityMode(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 1 byte
 	pkt[*pos] = 0x01
 	*pos++
 	return pkt, nil
 }
 
-func GetTotalPriorityQueueNumber(pos *uint, pkt []byte) ([]byte, error) {
+func GetTotalPriorityQueueNumber(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 2 bytes
 	// report 0 queues because thats what BRCM does...
 	numqueues := 0
@@ -133,21 +135,21 @@ func GetTotalPriorityQueueNumber(pos *uint, pkt []byte) ([]byte, error) {
 	return pkt, nil
 }
 
-func GetTotalTrafficSchedulerNumber(pos *uint, pkt []byte) ([]byte, error) {
+func GetTotalTrafficSchedulerNumber(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 1 byte
 	pkt[*pos] = 0x00
 	*pos++
 	return pkt, nil
 }
 
-func GetMode(pos *uint, pkt []byte) ([]byte, error) {
+func GetMode(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 1 byte
 	pkt[*pos] = 0x01
 	*pos++
 	return pkt, nil
 }
 
-func GetTotalGemPortIDNumber(pos *uint, pkt []byte) ([]byte, error) {
+func GetTotalGemPortIDNumber(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 2 bytes
 	gemports := 32
 	bs := make([]byte, 2)
@@ -159,7 +161,7 @@ func GetTotalGemPortIDNumber(pos *uint, pkt []byte) ([]byte, error) {
 	return pkt, nil
 }
 
-func GetSysUptime(pos *uint, pkt []byte) ([]byte, error) {
+func GetSysUptime(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 4 byte int
 	uptime := 0
 	bs := make([]byte, 4)
@@ -171,7 +173,7 @@ func GetSysUptime(pos *uint, pkt []byte) ([]byte, error) {
 	return pkt, nil
 }
 
-func GetConnectivityCapability(pos *uint, pkt []byte) ([]byte, error) {
+func GetConnectivityCapability(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 2 bytes
 	caps := []byte{0x00, 0x7F}
 	for _, ch := range caps {
@@ -181,14 +183,14 @@ func GetConnectivityCapability(pos *uint, pkt []byte) ([]byte, error) {
 	return pkt, nil
 }
 
-func GetCurrentConnectivityMode(pos *uint, pkt []byte) ([]byte, error) {
+func GetCurrentConnectivityMode(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 1 byte
 	pkt[*pos] = 0x00
 	*pos++
 	return pkt, nil
 }
 
-func GetQosConfigurationFlexibility(pos *uint, pkt []byte) ([]byte, error) {
+func GetQosConfigurationFlexibility(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 2 bytes
 	qosconf := []byte{0x00, 0x30}
 	for _, ch := range qosconf {
@@ -198,7 +200,7 @@ func GetQosConfigurationFlexibility(pos *uint, pkt []byte) ([]byte, error) {
 	return pkt, nil
 }
 
-func GetPriorityQueueScaleFactor(pos *uint, pkt []byte) ([]byte, error) {
+func GetPriorityQueueScaleFactor(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 1 bytes
 	pkt[*pos] = 0x01
 	*pos++
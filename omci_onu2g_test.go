@@ -0,0 +1,55 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestOnu2GGemPortsInUse(t *testing.T) {
+	onu := newTestOnu(t)
+	inUse := func() int {
+		n, err := GetGemPortsInUse(0, onu.intfId, onu.onuId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	if n := inUse(); n != 0 {
+		t.Errorf("%d GEM ports in use after a MibReset, expected 0", n)
+	}
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+	onu.mustCreate(GEMPortNetworkCTP, 0x0402, gemPortAttributes(0x0402))
+	if n := inUse(); n != 2 {
+		t.Errorf("%d GEM ports in use, expected 2", n)
+	}
+
+	if result := onu.result(onu.send(Delete, GEMPortNetworkCTP, 0x0401, nil)); result != Success {
+		t.Fatalf("Delete of a GEM port got result %d", result)
+	}
+	if n := inUse(); n != 1 {
+		t.Errorf("%d GEM ports in use after a Delete, expected 1", n)
+	}
+
+	// G.988 defines no ONU2-G attribute 15
+	resp := onu.send(Get, ONU2G, 0, []byte{0x00, 0x02})
+	if mask := binary.BigEndian.Uint16(resp[9:11]); mask != 0 {
+		t.Errorf("Get of the ONU2-G attribute 15 served mask %#04x, expected none", mask)
+	}
+}
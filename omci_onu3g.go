@@ -0,0 +1,55 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// ONU3-G attribute numbers
+const (
+	Onu3GFlashMemoryPerformanceValue = iota + 1
+	Onu3GLatestRestartReason
+	Onu3GTotalNumberOfStatusSnapshots
+	Onu3GNumberOfValidStatusSnapshots
+	Onu3GNextStatusSnapshotIndex
+	Onu3GStatusSnapshotRecordTable
+	Onu3GSnapAction
+	Onu3GMostRecentStatusSnapshot
+	Onu3GResetAction
+)
+
+// The status snapshots, the snap action and the reset action are not modeled, the ONU3-G reports no snapshot
+func init() {
+	MeDefinitions[ONU3G] = &MeDefinition{
+		Name: "Onu3G",
+		Attributes: []AttributeDefinition{
+			{Name: "FlashMemoryPerformanceValue", Size: 1, Access: AttrRead},
+			{Name: "LatestRestartReason", Size: 1, Access: AttrRead},
+			{Name: "TotalNumberOfStatusSnapshots", Size: 2, Access: AttrRead},
+			{Name: "NumberOfValidStatusSnapshots", Size: 2, Access: AttrRead},
+			{Name: "NextStatusSnapshotIndex", Size: 2, Access: AttrRead},
+			{Name: "StatusSnapshotRecordTable"},
+			{Name: "SnapAction", Size: 1},
+			{Name: "MostRecentStatusSnapshot"},
+			{Name: "ResetAction", Size: 1},
+		},
+		Instances: func() []uint16 {
+			return []uint16{0}
+		},
+		Init: func(key OnuKey, instance uint16, attrs MeAttributes) {
+			attrs[Onu3GFlashMemoryPerformanceValue][0] = Config.Onu3GFlashMemoryPerformance
+			attrs[Onu3GLatestRestartReason][0] = Config.Onu3GLatestRestartReason
+		},
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOnu3GFlashMemoryPerformance(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.Onu3GFlashMemoryPerformance = 95
+	onu := newTestOnu(t)
+
+	values := onu.mustGet(ONU3G, 0, attributeMaskBit(Onu3GFlashMemoryPerformanceValue))
+	if values[0] != 95 {
+		t.Errorf("Flash memory performance is %d, expected 95", values[0])
+	}
+}
+
+func TestOnu3GStatusSnapshots(t *testing.T) {
+	onu := newTestOnu(t)
+
+	// No snapshot is taken, the next one goes to the first record
+	mask := attributeMaskBit(Onu3GTotalNumberOfStatusSnapshots) | attributeMaskBit(Onu3GNumberOfValidStatusSnapshots) |
+		attributeMaskBit(Onu3GNextStatusSnapshotIndex)
+	values := onu.mustGet(ONU3G, 0, mask)
+	if !bytes.Equal(values[:6], make([]byte, 6)) {
+		t.Errorf("Status snapshot attributes are %x, expected zero", values[:6])
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ONU Data attribute numbers
+const (
+	OnuDataMibDataSync = iota + 1
+)
+
+func init() {
+	MeDefinitions[ONUData] = &MeDefinition{
+		Name: "OnuData",
+		Attributes: []AttributeDefinition{
+			// Reset to 0 by a MibReset
+			{Name: "MibDataSync", Size: 1, Access: AttrRead | AttrWrite},
+		},
+		Instances: func() []uint16 {
+			return []uint16{0}
+		},
+	}
+}
+
+// mibDataSync returns the MIB data sync counter of the ONU, checked by the OLT in its MIB audits
+func (s *OnuOmciState) mibDataSync() uint8 {
+	return s.mib[ONUData][0][OnuDataMibDataSync][0]
+}
+
+// incrementMibDataSync counts a change of the MIB by the OLT, the counter wraps from 255 to 1 as 0 is
+// reserved for a MIB just reset
+func (s *OnuOmciState) incrementMibDataSync() {
+	mds := s.mib[ONUData][0][OnuDataMibDataSync]
+	mds[0]++
+	if mds[0] == 0 {
+		mds[0] = 1
+	}
+}
+
+// trackMibDataSync increments the MIB data sync counter on every successful Create, Set or Delete,
+// and counts the Gets of the ONU Data
+func trackMibDataSync(next HandlerFunc) HandlerFunc {
+	return func(ctx *HandlerContext) ([]byte, error) {
+		resp, err := next(ctx)
+		if err != nil {
+			return resp, err
+		}
+
+		switch ctx.MsgType {
+		case Get:
+			if ctx.Class != ONUData {
+				return resp, err
+			}
+		case Create, Set, Delete:
+			// A Set of the counter itself is not a change of the MIB
+			if ctx.Class == ONUData || !isMibChanged(ctx, resp) {
+				return resp, err
+			}
+		default:
+			return resp, err
+		}
+
+		OnuOmciStateMapLock.Lock()
+		defer OnuOmciStateMapLock.Unlock()
+		state, ok := OnuOmciStateMap[ctx.Key]
+		if !ok {
+			return resp, err
+		}
+		if ctx.MsgType == Get {
+			state.onuDataPolls++
+		} else {
+			state.incrementMibDataSync()
+		}
+		return resp, err
+	}
+}
+
+// isMibChanged reports whether a Create, Set or Delete changed the MIB: it succeeded, or it is a Set
+// writing some of its attributes, the failed ones being in the attribute execution mask
+func isMibChanged(ctx *HandlerContext, resp []byte) bool {
+	offset := resultOffset(ctx.DeviceId)
+	if len(resp) <= offset {
+		return false
+	}
+	switch OmciResult(resp[offset]) {
+	case Success:
+		return true
+	case AttributeFailure:
+		// The contents of an extended request start with their length, the attribute execution mask
+		// follows the result and the optional-attribute mask in both message sets
+		maskOffset := 0
+		if ctx.DeviceId == ExtendedDeviceId {
+			maskOffset = 2
+		}
+		if ctx.MsgType != Set || len(ctx.Content) < maskOffset+2 || len(resp) < offset+5 {
+			return false
+		}
+		mask := binary.BigEndian.Uint16(ctx.Content[maskOffset:])
+		failed := binary.BigEndian.Uint16(resp[offset+3:])
+		return mask&^failed != 0
+	}
+	return false
+}
+
+// GetOnuDataPollCount returns how many times the OLT read the ONU Data of an ONU, e.g. to check its MIB audits
+func GetOnuDataPollCount(oltId int, intfId uint32, onuId uint32) (int, error) {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.RLock()
+	defer OnuOmciStateMapLock.RUnlock()
+	if state, ok := OnuOmciStateMap[key]; ok {
+		return state.onuDataPolls, nil
+	}
+	errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+	return 0, errors.New(errmsg)
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+)
+
+func TestOnuDataPolls(t *testing.T) {
+	onu := newTestOnu(t)
+	mask := attributeMaskBit(OnuDataMibDataSync)
+
+	for i := 0; i < 3; i++ {
+		if mds := onu.mustGet(ONUData, 0, mask)[0]; mds != 0 {
+			t.Errorf("MIB data sync is %d after a MibReset, expected 0", mds)
+		}
+	}
+	if polls, err := GetOnuDataPollCount(0, onu.intfId, onu.onuId); err != nil || polls != 3 {
+		t.Errorf("ONU Data poll count is %d (%v), expected 3", polls, err)
+	}
+
+	// The audit sees the change of the MIB
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+	if mds := onu.mustGet(ONUData, 0, mask)[0]; mds != 1 {
+		t.Errorf("MIB data sync is %d after a Create, expected 1", mds)
+	}
+	if polls, _ := GetOnuDataPollCount(0, onu.intfId, onu.onuId); polls != 4 {
+		t.Errorf("ONU Data poll count is %d, expected 4", polls)
+	}
+}
+
+func TestShortResponseMibDataSync(t *testing.T) {
+	defer ResetMiddlewares()
+	onu := newTestOnu(t)
+
+	// A Set answered with a response ending before its result
+	Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *HandlerContext) ([]byte, error) {
+			if ctx.Key.IntfId != onu.intfId || ctx.MsgType != Set {
+				return next(ctx)
+			}
+			return make([]byte, messageHeaderLength), nil
+		}
+	})
+
+	onu.sendFrame(EncodeRequest(onu.nextTxId(), Set, PPTPEthernetUNI, 257, []byte{0x08, 0x00, 0x01}))
+	if mds := onu.mustGet(ONUData, 0, attributeMaskBit(OnuDataMibDataSync))[0]; mds != 0 {
+		t.Errorf("MIB data sync is %d after a Set without result, expected 0", mds)
+	}
+}
+
+func TestExtendedResponseMibDataSync(t *testing.T) {
+	defer ResetMiddlewares()
+	onu := newTestOnu(t)
+
+	// An extended Set rejected with a contents length of 3, the result following it
+	Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *HandlerContext) ([]byte, error) {
+			if ctx.Key.IntfId != onu.intfId || ctx.MsgType != Set {
+				return next(ctx)
+			}
+			resp := make([]byte, ExtendedHeaderLength+3+ExtendedMicLength)
+			resp[9] = 3
+			resp[10] = byte(ParameterError)
+			return resp, nil
+		}
+	})
+
+	content := []byte{0x08, 0x00, 0x01}
+	resp := onu.sendFrame(EncodeExtendedRequest(onu.nextTxId(), Set, PPTPEthernetUNI, 257, content))
+	if result := ResponseResult(resp); result != ParameterError {
+		t.Fatalf("Extended Set got result %d, expected %d", result, ParameterError)
+	}
+	if mds := onu.mustGet(ONUData, 0, attributeMaskBit(OnuDataMibDataSync))[0]; mds != 0 {
+		t.Errorf("MIB data sync is %d after a rejected extended Set, expected 0", mds)
+	}
+}
+
+func TestExtendedPartialSetMibDataSync(t *testing.T) {
+	defer ResetMiddlewares()
+	onu := newTestOnu(t)
+
+	// An extended Set of two attributes answered with the attribute execution mask of failed
+	var failed uint16
+	Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *HandlerContext) ([]byte, error) {
+			if ctx.Key.IntfId != onu.intfId || ctx.MsgType != Set {
+				return next(ctx)
+			}
+			resp := make([]byte, ExtendedHeaderLength+5+ExtendedMicLength)
+			resp[9] = 5
+			resp[10] = byte(AttributeFailure)
+			resp[13] = byte(failed >> 8)
+			resp[14] = byte(failed & 0xFF)
+			return resp, nil
+		}
+	})
+
+	mask := attributeMaskBit(OnuDataMibDataSync)
+	content := []byte{0x0C, 0x00, 0x00, 0x00}
+	for _, test := range []struct {
+		failed uint16
+		mds    byte
+	}{
+		{0x0C00, 0}, // Neither attribute is written
+		{0x0800, 1}, // The second attribute is written
+	} {
+		failed = test.failed
+		onu.sendFrame(EncodeExtendedRequest(onu.nextTxId(), Set, PPTPEthernetUNI, 257, content))
+		if mds := onu.mustGet(ONUData, 0, mask)[0]; mds != test.mds {
+			t.Errorf("MIB data sync is %d after a Set failing attributes %#04x, expected %d", mds, test.failed, test.mds)
+		}
+	}
+}
@@ -16,6 +16,11 @@
 
 package core
 
+import (
+	"errors"
+	"fmt"
+)
+
 type OnuGAttributes int
 
 const (
@@ -35,6 +40,38 @@ const (
 	ExtendedTcLayerOptions   OnuGAttributes = 0x0008
 )
 
+// OnuGBatteryLowAlarm is the ONU-G alarm number of the battery-low alarm
+const OnuGBatteryLowAlarm uint = 4
+
+// onuGOperationalState is the attribute number of the ONU-G operational state
+const onuGOperationalState = 8
+
+// logicalIdLength is the length of the ONU-G logical ONU id and logical password
+const logicalIdLength = 24
+
+// onuGAttributeSizes are the sizes of the ONU-G attributes, to find the values in the contents of a Set
+var onuGAttributeSizes = map[OnuGAttributes]int{
+	VendorID:                 4,
+	Version:                  14,
+	SerialNumber:             8,
+	TrafficManagementOptions: 1,
+	VpVcCrossConnectOptions:  1,
+	BatteryBackup:            1,
+	AdministrativeState:      1,
+	OperationalState:         1,
+	OntSurvivalTime:          1,
+	LogicalOnuID:             logicalIdLength,
+	LogicalPassword:          logicalIdLength,
+	CredentialsStatus:        1,
+	ExtendedTcLayerOptions:   2,
+}
+
+// onuGWritableAttributes are the ONU-G attributes the OLT may set
+const onuGWritableAttributes = BatteryBackup | AdministrativeState | LogicalOnuID | LogicalPassword | CredentialsStatus
+
+// BatteryLowThreshold is the battery charge (in percent) below which the battery-low alarm is raised
+const BatteryLowThreshold = 20
+
 type OnuGAttributeHandler func(*uint, []byte, OnuKey) ([]byte, error)
 
 var OnuGAttributeHandlers = map[OnuGAttributes]OnuGAttributeHandler{
@@ -61,7 +98,13 @@ func GetOnuGAttributes(pos *uint, pkt []byte, content OmciContent, key OnuKey) (
 		reqAttribute := Attribute & AttributesMask
 
 		if reqAttribute != 0 {
-			pkt, _ = OnuGAttributeHandlers[OnuGAttributes(reqAttribute)](pos, pkt, key)
+			handler, ok := OnuGAttributeHandlers[OnuGAttributes(reqAttribute)]
+			if !ok {
+				// Attributes without a handler are left out of the response
+				AttributesMask &^= reqAttribute
+				continue
+			}
+			pkt, _ = handler(pos, pkt, key)
 		}
 	}
 
@@ -83,13 +126,9 @@ func GetVendorID(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	return pkt, nil
 }
 
-func GetVersion(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
+func GetVersion(pos *uint, pkt []byte, key OnuKey) ([]byte, error) {
 	// 14 bytes
-	for i := 1; i <= 14; i++ {
-		b := byte(' ')
-		pkt[*pos] = b
-		*pos++
-	}
+	putConfigString(pos, pkt, getOnuConfig(key).Version, versionLength, []byte("              "))
 	return pkt, nil
 }
 
@@ -119,9 +158,14 @@ func GetVpVcCrossConnectOptions(pos *uint, pkt []byte, _ OnuKey) ([]byte, error)
 	return pkt, nil
 }
 
-func GetBatteryBackup(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
+func GetBatteryBackup(pos *uint, pkt []byte, key OnuKey) ([]byte, error) {
 	// 1 byte
 	pkt[*pos] = 0x00
+	OnuOmciStateMapLock.RLock()
+	if state, ok := OnuOmciStateMap[key]; ok && state.batteryBackup {
+		pkt[*pos] = 0x01
+	}
+	OnuOmciStateMapLock.RUnlock()
 	*pos++
 	return pkt, nil
 }
@@ -142,29 +186,40 @@ func GetOperationalState(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 
 func GetOntSurvivalTime(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
 	// 1 byte
-	pkt[*pos] = 0x00
+	pkt[*pos] = Config.OnuGSurvivalTime
 	*pos++
 	return pkt, nil
 }
 
-func GetLogicalOnuID(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
+func GetLogicalOnuID(pos *uint, pkt []byte, key OnuKey) ([]byte, error) {
 	// 24 bytes
-	for i := 1; i <= 24; i++ {
-		b := byte(' ')
-		pkt[*pos] = b
-		*pos++
-	}
+	putLogicalId(pos, pkt, key, func(s *OnuOmciState) []byte { return s.logicalOnuId }, Config.OnuGLogicalOnuId)
 	return pkt, nil
 }
 
-func GetLogicalPassword(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
+func GetLogicalPassword(pos *uint, pkt []byte, key OnuKey) ([]byte, error) {
 	// 24 bytes
-	for i := 1; i <= 24; i++ {
-		b := byte(' ')
-		pkt[*pos] = b
+	putLogicalId(pos, pkt, key, func(s *OnuOmciState) []byte { return s.logicalPassword }, Config.OnuGLogicalPassword)
+	return pkt, nil
+}
+
+// putLogicalId writes the logical ONU id or password set by the OLT, or the configured one if none was set
+func putLogicalId(pos *uint, pkt []byte, key OnuKey, get func(*OnuOmciState) []byte, config string) {
+	var value []byte
+	OnuOmciStateMapLock.RLock()
+	if state, ok := OnuOmciStateMap[key]; ok {
+		value = get(state)
+	}
+	OnuOmciStateMapLock.RUnlock()
+
+	if value == nil {
+		putConfigString(pos, pkt, config, logicalIdLength, []byte("                        "))
+		return
+	}
+	for _, ch := range value {
+		pkt[*pos] = ch
 		*pos++
 	}
-	return pkt, nil
 }
 
 func GetCredentialsStatus(pos *uint, pkt []byte, _ OnuKey) ([]byte, error) {
@@ -183,3 +238,88 @@ func GetExtendedTcLayerOptions(pos *uint, pkt []byte, _ OnuKey) ([]byte, error)
 	}
 	return pkt, nil
 }
+
+// setOnuGAttributes applies a Set of the ONU-G, only the logical ONU id and password are stored and the
+// read-only attributes fail. It returns the result and the attribute execution mask, it is called with
+// OnuOmciStateMapLock held.
+func (s *OnuOmciState) setOnuGAttributes(content OmciContent) (OmciResult, uint16) {
+	r := NewContentReader(content[:])
+	mask := r.ReadMask()
+
+	var logicalOnuId, logicalPassword []byte
+	var failed uint16
+	for index := uint(16); index >= 1; index-- {
+		attribute := OnuGAttributes(1 << (index - 1))
+		if OnuGAttributes(mask)&attribute == 0 {
+			continue
+		}
+		value, err := r.ReadBytes(onuGAttributeSizes[attribute])
+		if err != nil {
+			// The mask claims more attributes than the contents carry
+			return ProcessingError, 0
+		}
+		if onuGWritableAttributes&attribute == 0 {
+			failed |= uint16(attribute)
+			continue
+		}
+		switch attribute {
+		case LogicalOnuID:
+			logicalOnuId = append([]byte{}, value...)
+		case LogicalPassword:
+			logicalPassword = append([]byte{}, value...)
+		}
+	}
+
+	if logicalOnuId != nil {
+		s.logicalOnuId = logicalOnuId
+	}
+	if logicalPassword != nil {
+		s.logicalPassword = logicalPassword
+	}
+	if failed != 0 {
+		return AttributeFailure, failed
+	}
+	return Success, 0
+}
+
+// SetBatteryBackup configures whether the ONU reports a battery backup in the ONU-G
+func SetBatteryBackup(oltId int, intfId uint32, onuId uint32, enabled bool) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer unlockAndNotify()
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+		return errors.New(errmsg)
+	}
+	state.batteryBackup = enabled
+	if !enabled {
+		// Without a battery there is nothing left to be low on
+		state.setAlarm(key, ONUG, 0, OnuGBatteryLowAlarm, false)
+	}
+	return nil
+}
+
+// SimulateBatteryLevel sets the remaining battery charge (in percent) of an ONU with battery backup,
+// raising the ONU-G battery-low alarm when it drops below BatteryLowThreshold and clearing it once recharged
+func SimulateBatteryLevel(oltId int, intfId uint32, onuId uint32, level uint8) error {
+	if level > 100 {
+		return fmt.Errorf("Invalid battery level %d%%", level)
+	}
+
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer unlockAndNotify()
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+		return errors.New(errmsg)
+	}
+	if !state.batteryBackup {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Battery backup is not enabled", intfId, onuId)
+		return errors.New(errmsg)
+	}
+	state.batteryLevel = level
+	state.setAlarm(key, ONUG, 0, OnuGBatteryLowAlarm, level < BatteryLowThreshold)
+	return nil
+}
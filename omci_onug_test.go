@@ -0,0 +1,150 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBatteryLowAlarm(t *testing.T) {
+	onu := newTestOnu(t)
+	if err := SetBatteryBackup(0, onu.intfId, onu.onuId, true); err != nil {
+		t.Fatal(err)
+	}
+	if values := onu.mustGet(ONUG, 0, uint16(BatteryBackup)); values[0] != 0x01 {
+		t.Errorf("Battery backup is %d, expected 1", values[0])
+	}
+
+	if err := SimulateBatteryLevel(0, onu.intfId, onu.onuId, BatteryLowThreshold-1); err != nil {
+		t.Fatal(err)
+	}
+	msg := onu.notification()
+	if msg.Type != AlarmRaised || !alarmRaised(msg.Packet, OnuGBatteryLowAlarm) {
+		t.Fatalf("Expected the battery-low alarm to be raised, got %s %x", msg.Type, msg.Packet)
+	}
+
+	// The alarm is only notified when it changes
+	if err := SimulateBatteryLevel(0, onu.intfId, onu.onuId, 1); err != nil {
+		t.Fatal(err)
+	}
+	onu.expectNoNotification()
+
+	if err := SimulateBatteryLevel(0, onu.intfId, onu.onuId, 100); err != nil {
+		t.Fatal(err)
+	}
+	msg = onu.notification()
+	if msg.Type != AlarmCleared || alarmRaised(msg.Packet, OnuGBatteryLowAlarm) {
+		t.Fatalf("Expected the battery-low alarm to be cleared, got %s %x", msg.Type, msg.Packet)
+	}
+}
+
+func TestBatteryLowAlarmAfterMibReset(t *testing.T) {
+	onu := newTestOnu(t)
+	if err := SetBatteryBackup(0, onu.intfId, onu.onuId, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := SimulateBatteryLevel(0, onu.intfId, onu.onuId, 1); err != nil {
+		t.Fatal(err)
+	}
+	onu.notification()
+
+	// The MIB reset clears the alarm, raising it again is notified
+	if result := onu.result(onu.send(MibReset, ONUData, 0, nil)); result != Success {
+		t.Fatalf("MibReset failed with result %d", result)
+	}
+	if err := SimulateBatteryLevel(0, onu.intfId, onu.onuId, 2); err != nil {
+		t.Fatal(err)
+	}
+	if msg := onu.notification(); msg.Type != AlarmRaised {
+		t.Fatalf("Got %s %x, expected the battery-low alarm", msg.Type, msg.Packet)
+	}
+}
+
+func TestBatteryLowAlarmWithFullChannel(t *testing.T) {
+	onu := newTestOnu(t)
+	if err := SetBatteryBackup(0, onu.intfId, onu.onuId, true); err != nil {
+		t.Fatal(err)
+	}
+	for len(GetChannel()) < cap(GetChannel()) {
+		GetChannel() <- OmciChMessage{}
+	}
+	defer drainChannel()
+
+	raised := make(chan error, 1)
+	go func() {
+		raised <- SimulateBatteryLevel(0, onu.intfId, onu.onuId, 1)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// The alarm waits for room on the channel without holding the state of the ONUs
+	read := make(chan istate, 1)
+	go func() {
+		read <- GetOnuOmciState(0, onu.intfId, onu.onuId)
+	}()
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("The ONU state is locked while the alarm is sent")
+	}
+
+	drainChannel()
+	if err := <-raised; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatteryLevelWithoutBackup(t *testing.T) {
+	onu := newTestOnu(t)
+	if values := onu.mustGet(ONUG, 0, uint16(BatteryBackup)); values[0] != 0x00 {
+		t.Errorf("Battery backup is %d, expected 0", values[0])
+	}
+	if err := SimulateBatteryLevel(0, onu.intfId, onu.onuId, 10); err == nil {
+		t.Error("Expected an error for an ONU without battery backup")
+	}
+}
+
+func TestLogicalOnuId(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.OnuGSurvivalTime = 5
+	Config.OnuGLogicalOnuId = "configured"
+	onu := newTestOnu(t)
+
+	values := onu.mustGet(ONUG, 0, uint16(OntSurvivalTime|LogicalOnuID))
+	if values[0] != 5 {
+		t.Errorf("Survival time is %d, expected 5", values[0])
+	}
+	if id := values[1 : 1+logicalIdLength]; !bytes.Equal(id[:len("configured")], []byte("configured")) {
+		t.Errorf("Logical ONU id is %q, expected the configured one", id)
+	}
+
+	registrationId := make([]byte, logicalIdLength)
+	copy(registrationId, "registration-id")
+	if result, failed := onu.set(ONUG, 0, uint16(LogicalOnuID), registrationId...); result != Success {
+		t.Fatalf("Set of the logical ONU id got result %d failed mask %#04x", result, failed)
+	}
+	if id := onu.mustGet(ONUG, 0, uint16(LogicalOnuID))[:logicalIdLength]; !bytes.Equal(id, registrationId) {
+		t.Errorf("Logical ONU id is %q, expected %q", id, registrationId)
+	}
+
+	// The survival time is read-only
+	if result, failed := onu.set(ONUG, 0, uint16(OntSurvivalTime), 1); result != AttributeFailure || failed != uint16(OntSurvivalTime) {
+		t.Errorf("Set of the survival time got result %d failed mask %#04x, expected %d and %#04x",
+			result, failed, AttributeFailure, uint16(OntSurvivalTime))
+	}
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TcaDefinition ties a PM counter to the threshold it is checked against and to the
+// threshold crossing alert raised in the PM ME alarm bitmap when it is reached
+type TcaDefinition struct {
+	Attribute int  // Counter attribute number
+	Alarm     uint // TCA number, the bit of the alarm bitmap
+	Threshold int  // Threshold value number, 1-7 in Threshold Data 1 and 8-14 in Threshold Data 2
+}
+
+func (d *MeDefinition) tcaOf(attribute int) (TcaDefinition, bool) {
+	for _, tca := range d.Tcas {
+		if tca.Attribute == attribute {
+			return tca, true
+		}
+	}
+	return TcaDefinition{}, false
+}
+
+// counterValue decodes a big-endian PM counter of up to 8 bytes
+func counterValue(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func putCounterValue(b []byte, v uint64) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+}
+
+func getPmState(key OnuKey, class OmciClass) (*OnuOmciState, *MeDefinition, error) {
+	def, ok := MeDefinitions[class]
+	if !ok || len(def.Tcas) == 0 {
+		return nil, nil, fmt.Errorf("ME class %s is not a PM ME", class.PrettyPrint())
+	}
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", key.IntfId, key.OnuId)
+		return nil, nil, errors.New(errmsg)
+	}
+	return state, def, nil
+}
+
+// pmIntervalEndTime is the attribute of every PM ME numbering its last completed interval
+const pmIntervalEndTime = 1
+
+// pmThreshold returns a threshold value of a PM ME instance, a value set with SetPmThreshold
+// takes precedence over the one provisioned in the Threshold Data MEs
+func (s *OnuOmciState) pmThreshold(class OmciClass, instance uint16, threshold int) uint64 {
+	if value, ok := s.pmThresholds[OmciMessageIdentifier{Class: class, Instance: instance}][threshold]; ok {
+		return value
+	}
+
+	attrs, ok := s.getMe(class, instance)
+	def := MeDefinitions[class]
+	if !ok || def.ThresholdDataId == 0 {
+		return 0
+	}
+	thresholdData, index := ThresholdData1, threshold
+	if threshold > thresholdValuesPerMe {
+		thresholdData, index = ThresholdData2, threshold-thresholdValuesPerMe
+	}
+	values, ok := s.getMe(thresholdData, attrs.uint16(def.ThresholdDataId))
+	if !ok {
+		return 0
+	}
+	return counterValue(values[index])
+}
+
+// SetPmThreshold sets a threshold value of a PM ME instance, the TCAs checked against it are raised
+// when their counter reaches it. A zero value disables the TCAs.
+func SetPmThreshold(oltId int, intfId uint32, onuId uint32, class OmciClass, instance uint16, threshold int, value uint64) error {
+	if threshold < 1 || threshold > 14 {
+		return fmt.Errorf("Invalid threshold value number %d", threshold)
+	}
+
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	state, _, err := getPmState(key, class)
+	if err != nil {
+		return err
+	}
+
+	id := OmciMessageIdentifier{Class: class, Instance: instance}
+	if _, ok := state.pmThresholds[id]; !ok {
+		state.pmThresholds[id] = map[int]uint64{}
+	}
+	state.pmThresholds[id][threshold] = value
+	return nil
+}
+
+// isCounter reports whether an attribute of a PM ME is a counter, with or without a TCA
+func (d *MeDefinition) isCounter(attribute int) bool {
+	if _, ok := d.tcaOf(attribute); ok {
+		return true
+	}
+	for _, counter := range d.Counters {
+		if counter == attribute {
+			return true
+		}
+	}
+	return false
+}
+
+// currentPmCounters returns the counters of the current interval of a PM ME instance,
+// the counters read by a Get being the ones of the last completed interval
+func (s *OnuOmciState) currentPmCounters(class OmciClass, instance uint16) MeAttributes {
+	id := OmciMessageIdentifier{Class: class, Instance: instance}
+	if counters, ok := s.pmCurrent[id]; ok {
+		return counters
+	}
+
+	def := MeDefinitions[class]
+	counters := MeAttributes{}
+	for i, attrDef := range def.Attributes {
+		if def.isCounter(i + 1) {
+			counters[i+1] = attrDef.defaultValue()
+		}
+	}
+	s.pmCurrent[id] = counters
+	return counters
+}
+
+// getCurrentMeAttributes fills pkt with the requested attributes of a PM ME instance, the
+// counters being the ones of the current interval
+func (s *OnuOmciState) getCurrentMeAttributes(class OmciClass, instance uint16, mask uint16, pkt []byte) OmciResult {
+	history, ok := s.getMe(class, instance)
+	if !ok {
+		pkt[9] = 0x00
+		pkt[10] = 0x00
+		return UnknownInstance
+	}
+
+	attrs := MeAttributes{}
+	for index, value := range history {
+		attrs[index] = value
+	}
+	for index, value := range s.currentPmCounters(class, instance) {
+		attrs[index] = value
+	}
+	return s.fillMeAttributes(class, instance, attrs, mask, pkt)
+}
+
+// IncrementPmCounter adds delta to a counter of the current interval of a PM ME instance
+// created by the OLT, raising the counter TCA when the counter crosses its threshold
+func IncrementPmCounter(oltId int, intfId uint32, onuId uint32, class OmciClass, instance uint16, attribute int, delta uint64) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer unlockAndNotify()
+	state, def, err := getPmState(key, class)
+	if err != nil {
+		return err
+	}
+	if _, ok := state.getMe(class, instance); !ok {
+		return fmt.Errorf("ME %s instance %d is not created", class.PrettyPrint(), instance)
+	}
+	if !def.isCounter(attribute) {
+		return fmt.Errorf("Attribute %d of ME %s is not a counter", attribute, class.PrettyPrint())
+	}
+
+	// Counters saturate instead of wrapping around
+	value := state.currentPmCounters(class, instance)[attribute]
+	max := ^uint64(0) >> uint(64-8*len(value))
+	old := counterValue(value)
+	current := old + delta
+	if current < old || current > max {
+		current = max
+	}
+	putCounterValue(value, current)
+
+	tca, ok := def.tcaOf(attribute)
+	if !ok {
+		return nil
+	}
+	threshold := state.pmThreshold(class, instance, tca.Threshold)
+	if threshold != 0 && old < threshold && current >= threshold {
+		state.setAlarm(key, class, instance, tca.Alarm, true)
+	}
+	return nil
+}
+
+// RolloverPmIntervals ends the current 15 minute interval of the PM MEs of an ONU: the counters
+// of the current interval become the ones read by a Get, the TCAs are cleared and the interval
+// end time is incremented
+func RolloverPmIntervals(oltId int, intfId uint32, onuId uint32) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer unlockAndNotify()
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+		return errors.New(errmsg)
+	}
+
+	for class, def := range MeDefinitions {
+		if len(def.Tcas) == 0 {
+			continue
+		}
+		for instance, attrs := range state.mib[class] {
+			for index, value := range state.currentPmCounters(class, instance) {
+				attrs[index] = value
+			}
+			delete(state.pmCurrent, OmciMessageIdentifier{Class: class, Instance: instance})
+
+			// The interval end time wraps around every 256 intervals
+			attrs[pmIntervalEndTime][0]++
+
+			for _, tca := range def.Tcas {
+				state.setAlarm(key, class, instance, tca.Alarm, false)
+			}
+		}
+	}
+	return nil
+}
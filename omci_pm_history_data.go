@@ -16,6 +16,7 @@
 
 package core
 
+// Deprecated: the Ethernet PM History Data attributes are identified by their number, see EthernetPmIntervalEndTime
 type PerformanceMonitoringHistoryData int
 
 const (
@@ -38,10 +39,76 @@ const (
 	InternalMACReceiveErrorCounter  PerformanceMonitoringHistoryData = 0x0001
 )
 
+// Ethernet PM History Data attribute numbers
+const (
+	EthernetPmIntervalEndTime = iota + 1
+	EthernetPmThresholdDataId
+	EthernetPmFcsErrors
+	EthernetPmExcessiveCollisionCounter
+	EthernetPmLateCollisionCounter
+	EthernetPmFramesTooLong
+	EthernetPmBufferOverflowsOnReceive
+	EthernetPmBufferOverflowsOnTransmit
+	EthernetPmSingleCollisionFrameCounter
+	EthernetPmMultipleCollisionsFrameCounter
+	EthernetPmSqeCounter
+	EthernetPmDeferredTransmissionCounter
+	EthernetPmInternalMacTransmitErrorCounter
+	EthernetPmCarrierSenseErrorCounter
+	EthernetPmAlignmentErrorCounter
+	EthernetPmInternalMacReceiveErrorCounter
+)
+
+func init() {
+	// With the hardware, it is seen that all counters are 0x00
+	MeDefinitions[EthernetPMHistoryData] = &MeDefinition{
+		Name: "EthernetPmHistoryData",
+		Attributes: []AttributeDefinition{
+			{Name: "IntervalEndTime", Size: 1, Access: AttrRead},
+			{Name: "ThresholdData12Id", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "FcsErrors", Size: 4, Access: AttrRead},
+			{Name: "ExcessiveCollisionCounter", Size: 4, Access: AttrRead},
+			{Name: "LateCollisionCounter", Size: 4, Access: AttrRead},
+			{Name: "FramesTooLong", Size: 4, Access: AttrRead},
+			{Name: "BufferOverflowsOnReceive", Size: 4, Access: AttrRead},
+			{Name: "BufferOverflowsOnTransmit", Size: 4, Access: AttrRead},
+			{Name: "SingleCollisionFrameCounter", Size: 4, Access: AttrRead},
+			{Name: "MultipleCollisionsFrameCounter", Size: 4, Access: AttrRead},
+			{Name: "SqeCounter", Size: 4, Access: AttrRead},
+			{Name: "DeferredTransmissionCounter", Size: 4, Access: AttrRead},
+			{Name: "InternalMacTransmitErrorCounter", Size: 4, Access: AttrRead},
+			{Name: "CarrierSenseErrorCounter", Size: 4, Access: AttrRead},
+			{Name: "AlignmentErrorCounter", Size: 4, Access: AttrRead},
+			{Name: "InternalMacReceiveErrorCounter", Size: 4, Access: AttrRead},
+		},
+		ThresholdDataId: EthernetPmThresholdDataId,
+		// Each counter has its own TCA, in attribute order
+		Tcas: []TcaDefinition{
+			{Attribute: EthernetPmFcsErrors, Alarm: 0, Threshold: 1},
+			{Attribute: EthernetPmExcessiveCollisionCounter, Alarm: 1, Threshold: 2},
+			{Attribute: EthernetPmLateCollisionCounter, Alarm: 2, Threshold: 3},
+			{Attribute: EthernetPmFramesTooLong, Alarm: 3, Threshold: 4},
+			{Attribute: EthernetPmBufferOverflowsOnReceive, Alarm: 4, Threshold: 5},
+			{Attribute: EthernetPmBufferOverflowsOnTransmit, Alarm: 5, Threshold: 6},
+			{Attribute: EthernetPmSingleCollisionFrameCounter, Alarm: 6, Threshold: 7},
+			{Attribute: EthernetPmMultipleCollisionsFrameCounter, Alarm: 7, Threshold: 8},
+			{Attribute: EthernetPmSqeCounter, Alarm: 8, Threshold: 9},
+			{Attribute: EthernetPmDeferredTransmissionCounter, Alarm: 9, Threshold: 10},
+			{Attribute: EthernetPmInternalMacTransmitErrorCounter, Alarm: 10, Threshold: 11},
+			{Attribute: EthernetPmCarrierSenseErrorCounter, Alarm: 11, Threshold: 12},
+			{Attribute: EthernetPmAlignmentErrorCounter, Alarm: 12, Threshold: 13},
+			{Attribute: EthernetPmInternalMacReceiveErrorCounter, Alarm: 13, Threshold: 14},
+		},
+	}
+}
+
+// Deprecated: the Ethernet PM History Data is served from MeDefinitions, see PMHistoryAttributeHandlers
 type PMHistoryAttributeHandler func(*uint, []byte) ([]byte, error)
 
+// Deprecated: the Ethernet PM History Data is served from MeDefinitions, the handlers write the default values
+// of its attributes
 var PMHistoryAttributeHandlers = map[PerformanceMonitoringHistoryData]ANIGAttributeHandler{
-	IntervalEndTime :                GetIntervalEndTime,
+	IntervalEndTime:                 GetIntervalEndTime,
 	ThresholdDataId:                 GetThresholdDataId,
 	FCSErrors:                       GetFCSErrors,
 	ExcessiveCollisionCounter:       GetExcessiveCollisionCounter,
@@ -59,6 +126,8 @@ var PMHistoryAttributeHandlers = map[PerformanceMonitoringHistoryData]ANIGAttrib
 	InternalMACReceiveErrorCounter:  GetInternalMACReceiveErrorCounter,
 }
 
+// Deprecated: the Ethernet PM History Data is served from MeDefinitions, GetEthernetPMHistoryDataAttributes
+// writes the default values of the attributes of content
 func GetEthernetPMHistoryDataAttributes(pos *uint, pkt []byte, content OmciContent) ([]byte, error) {
 	AttributesMask := getAttributeMask(content)
 
@@ -79,129 +148,82 @@ func GetEthernetPMHistoryDataAttributes(pos *uint, pkt []byte, content OmciConte
 
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetIntervalEndTime(pos *uint, pkt []byte) ([]byte, error) {
-	// With the hardware, it is seen that all attributes are 0x00
-	// Nevertheless these functions are made to provide specific values in future if required
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmIntervalEndTime, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetThresholdDataId(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmThresholdDataId, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetFCSErrors(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmFcsErrors, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetExcessiveCollisionCounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmExcessiveCollisionCounter, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetLateCollisionCounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmLateCollisionCounter, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetFrameTooLong(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmFramesTooLong, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetBufferOverflowOnReceive(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmBufferOverflowsOnReceive, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetBufferOverflowOnTransmit(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmBufferOverflowsOnTransmit, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetSingleCollisionFrameCounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmSingleCollisionFrameCounter, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetMultipleCollisionFrameCounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmMultipleCollisionsFrameCounter, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetSQECounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmSqeCounter, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetDeferredTransmissionCounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmDeferredTransmissionCounter, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetInternalMACTransmitErrorCounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmInternalMacTransmitErrorCounter, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetCarrierSenseErrorCounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmCarrierSenseErrorCounter, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetAllignmentErrorCounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmAlignmentErrorCounter, pos, pkt)
 }
 
+// Deprecated: use PMHistoryAttributeHandlers
 func GetInternalMACReceiveErrorCounter(pos *uint, pkt []byte) ([]byte, error) {
-	*pos++
-	*pos++
-	*pos++
-	*pos++
-	return pkt, nil
+	return writeDefaultAttribute(EthernetPMHistoryData, EthernetPmInternalMacReceiveErrorCounter, pos, pkt)
 }
-
-
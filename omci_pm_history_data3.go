@@ -0,0 +1,81 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// Ethernet PM History Data 3 attribute numbers
+const (
+	EthernetPm3IntervalEndTime = iota + 1
+	EthernetPm3ThresholdDataId
+	EthernetPm3DropEvents
+	EthernetPm3Octets
+	EthernetPm3Packets
+	EthernetPm3BroadcastPackets
+	EthernetPm3MulticastPackets
+	EthernetPm3UndersizePackets
+	EthernetPm3Fragments
+	EthernetPm3Jabbers
+	EthernetPm3Packets64Octets
+	EthernetPm3Packets65To127Octets
+	EthernetPm3Packets128To255Octets
+	EthernetPm3Packets256To511Octets
+	EthernetPm3Packets512To1023Octets
+	EthernetPm3Packets1024To1518Octets
+)
+
+func init() {
+	MeDefinitions[EthernetPMHistoryData3] = &MeDefinition{
+		Name: "EthernetPmHistoryData3",
+		Attributes: []AttributeDefinition{
+			{Name: "IntervalEndTime", Size: 1, Access: AttrRead},
+			{Name: "ThresholdData12Id", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "DropEvents", Size: 4, Access: AttrRead},
+			{Name: "Octets", Size: 4, Access: AttrRead},
+			{Name: "Packets", Size: 4, Access: AttrRead},
+			{Name: "BroadcastPackets", Size: 4, Access: AttrRead},
+			{Name: "MulticastPackets", Size: 4, Access: AttrRead},
+			{Name: "UndersizePackets", Size: 4, Access: AttrRead},
+			{Name: "Fragments", Size: 4, Access: AttrRead},
+			{Name: "Jabbers", Size: 4, Access: AttrRead},
+			{Name: "Packets64Octets", Size: 4, Access: AttrRead},
+			{Name: "Packets65To127Octets", Size: 4, Access: AttrRead},
+			{Name: "Packets128To255Octets", Size: 4, Access: AttrRead},
+			{Name: "Packets256To511Octets", Size: 4, Access: AttrRead},
+			{Name: "Packets512To1023Octets", Size: 4, Access: AttrRead},
+			{Name: "Packets1024To1518Octets", Size: 4, Access: AttrRead},
+		},
+		ThresholdDataId: EthernetPm3ThresholdDataId,
+		// Only the error counters have a TCA
+		Tcas: []TcaDefinition{
+			{Attribute: EthernetPm3DropEvents, Alarm: 0, Threshold: 1},
+			{Attribute: EthernetPm3UndersizePackets, Alarm: 1, Threshold: 2},
+			{Attribute: EthernetPm3Fragments, Alarm: 2, Threshold: 3},
+			{Attribute: EthernetPm3Jabbers, Alarm: 3, Threshold: 4},
+		},
+		Counters: []int{
+			EthernetPm3Octets,
+			EthernetPm3Packets,
+			EthernetPm3BroadcastPackets,
+			EthernetPm3MulticastPackets,
+			EthernetPm3Packets64Octets,
+			EthernetPm3Packets65To127Octets,
+			EthernetPm3Packets128To255Octets,
+			EthernetPm3Packets256To511Octets,
+			EthernetPm3Packets512To1023Octets,
+			EthernetPm3Packets1024To1518Octets,
+		},
+	}
+}
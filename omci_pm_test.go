@@ -0,0 +1,196 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestPmThresholdCrossingAlerts(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(EthernetPMHistoryData, 0x0101, map[int][]byte{EthernetPmThresholdDataId: {0x00, 0x00}})
+	for _, threshold := range []int{1, 3} {
+		if err := SetPmThreshold(0, onu.intfId, onu.onuId, EthernetPMHistoryData, 0x0101, threshold, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, counter := range []int{EthernetPmFcsErrors, EthernetPmLateCollisionCounter} {
+		if err := IncrementPmCounter(0, onu.intfId, onu.onuId, EthernetPMHistoryData, 0x0101, counter, 5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	onu.expectNoNotification()
+
+	var msg OmciChMessage
+	for _, counter := range []int{EthernetPmFcsErrors, EthernetPmLateCollisionCounter} {
+		if err := IncrementPmCounter(0, onu.intfId, onu.onuId, EthernetPMHistoryData, 0x0101, counter, 5); err != nil {
+			t.Fatal(err)
+		}
+		msg = onu.notification()
+		if msg.Type != AlarmRaised {
+			t.Fatalf("Got %s, expected %s", msg.Type, AlarmRaised)
+		}
+	}
+
+	// The TCA of the FCS errors is the first bit and the one of the late collisions the third
+	for alarm := uint(0); alarm < 14; alarm++ {
+		if raised := alarmRaised(msg.Packet, alarm); raised != (alarm == 0 || alarm == 2) {
+			t.Errorf("TCA %d raised is %t in %x", alarm, raised, msg.Packet[8:10])
+		}
+	}
+}
+
+func TestGetEthernetPMHistoryDataAttributes(t *testing.T) {
+	pkt := make([]byte, BaselineFrameLength)
+	pos := uint(getAttributesStart)
+	pkt, _ = GetEthernetPMHistoryDataAttributes(&pos, pkt, OmciContent{0xE0, 0x00})
+
+	// The interval end time, the threshold data id and the FCS errors, all zero
+	if mask := binary.BigEndian.Uint16(pkt[9:11]); mask != uint16(IntervalEndTime|ThresholdDataId|FCSErrors) {
+		t.Errorf("Attribute mask is %#04x", mask)
+	}
+	if pos != getAttributesStart+7 {
+		t.Errorf("Position is %d, expected %d", pos, getAttributesStart+7)
+	}
+}
+
+func TestEthernetPm3GetCurrentData(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(EthernetPMHistoryData3, 0x0101, map[int][]byte{EthernetPm3ThresholdDataId: {0x00, 0x00}})
+	if err := IncrementPmCounter(0, onu.intfId, onu.onuId, EthernetPMHistoryData3, 0x0101, EthernetPm3Octets, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	mask := attributeMaskBit(EthernetPm3Octets)
+	currentData := func() uint32 {
+		resp := onu.send(GetCurrentData, EthernetPMHistoryData3, 0x0101, []byte{byte(mask >> 8), byte(mask)})
+		if result := onu.result(resp); result != Success {
+			t.Fatalf("GetCurrentData got result %d", result)
+		}
+		return binary.BigEndian.Uint32(resp[getAttributesStart:])
+	}
+	if octets := currentData(); octets != 1000 {
+		t.Errorf("Current interval octets are %d, expected 1000", octets)
+	}
+	if octets := binary.BigEndian.Uint32(onu.mustGet(EthernetPMHistoryData3, 0x0101, mask)); octets != 0 {
+		t.Errorf("Last interval octets are %d before the rollover, expected 0", octets)
+	}
+
+	if err := RolloverPmIntervals(0, onu.intfId, onu.onuId); err != nil {
+		t.Fatal(err)
+	}
+	if octets := binary.BigEndian.Uint32(onu.mustGet(EthernetPMHistoryData3, 0x0101, mask)); octets != 1000 {
+		t.Errorf("Last interval octets are %d after the rollover, expected 1000", octets)
+	}
+	if octets := currentData(); octets != 0 {
+		t.Errorf("Current interval octets are %d after the rollover, expected 0", octets)
+	}
+}
+
+func TestEnhancedFecPmGetCurrentData(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(EnhancedFecPMHistoryData, 0x0101, map[int][]byte{EnhancedFecPmThresholdDataId: {0x00, 0x00}})
+	increment := func(delta uint64) {
+		err := IncrementPmCounter(0, onu.intfId, onu.onuId, EnhancedFecPMHistoryData, 0x0101, EnhancedFecPmCorrectedBytes, delta)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	increment(5000)
+	if err := RolloverPmIntervals(0, onu.intfId, onu.onuId); err != nil {
+		t.Fatal(err)
+	}
+	increment(300)
+
+	mask := attributeMaskBit(EnhancedFecPmIntervalEndTime) | attributeMaskBit(EnhancedFecPmCorrectedBytes)
+	resp := onu.send(GetCurrentData, EnhancedFecPMHistoryData, 0x0101, []byte{byte(mask >> 8), byte(mask)})
+	if result := onu.result(resp); result != Success {
+		t.Fatalf("GetCurrentData got result %d", result)
+	}
+	if corrected := binary.BigEndian.Uint64(resp[getAttributesStart+1:]); corrected != 300 {
+		t.Errorf("Current interval corrected bytes are %d, expected 300", corrected)
+	}
+
+	values := onu.mustGet(EnhancedFecPMHistoryData, 0x0101, mask)
+	if values[0] != 1 {
+		t.Errorf("Interval end time is %d, expected 1", values[0])
+	}
+	if corrected := binary.BigEndian.Uint64(values[1:]); corrected != 5000 {
+		t.Errorf("Last interval corrected bytes are %d, expected 5000", corrected)
+	}
+}
+
+func TestEthernetFrameExtendedPm64BitCounter(t *testing.T) {
+	onu := newTestOnu(t)
+	controlBlock := map[int][]byte{EthernetExtPmControlBlock: make([]byte, 16)}
+	onu.mustCreate(EthernetFrameExtendedPM64Bit, 0x0101, controlBlock)
+	onu.mustCreate(EthernetFrameExtendedPM, 0x0101, controlBlock)
+
+	// Past 2^32 octets
+	for _, class := range []OmciClass{EthernetFrameExtendedPM64Bit, EthernetFrameExtendedPM} {
+		for _, delta := range []uint64{1<<32 - 1, 6} {
+			if err := IncrementPmCounter(0, onu.intfId, onu.onuId, class, 0x0101, EthernetExtPmOctets, delta); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	mask := attributeMaskBit(EthernetExtPmOctets)
+	resp := onu.send(GetCurrentData, EthernetFrameExtendedPM64Bit, 0x0101, []byte{byte(mask >> 8), byte(mask)})
+	if octets := binary.BigEndian.Uint64(resp[getAttributesStart:]); octets != 1<<32+5 {
+		t.Errorf("Current interval octets are %d, expected %d", octets, uint64(1<<32+5))
+	}
+	if err := RolloverPmIntervals(0, onu.intfId, onu.onuId); err != nil {
+		t.Fatal(err)
+	}
+	if octets := binary.BigEndian.Uint64(onu.mustGet(EthernetFrameExtendedPM64Bit, 0x0101, mask)); octets != 1<<32+5 {
+		t.Errorf("Last interval octets are %d, expected %d", octets, uint64(1<<32+5))
+	}
+
+	// The 32-bit counter saturates
+	if octets := binary.BigEndian.Uint32(onu.mustGet(EthernetFrameExtendedPM, 0x0101, mask)); octets != 1<<32-1 {
+		t.Errorf("Last interval 32-bit octets are %d, expected %d", octets, uint32(1<<32-1))
+	}
+}
+
+func TestMacBridgePmLearningEntryDiscardCount(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(MacBridgeServiceProfile, 0x0201, nil)
+	onu.mustCreate(MacBridgePMHistoryData, 0x0201, map[int][]byte{MacBridgePmThresholdDataId: {0x00, 0x00}})
+	if err := IncrementPmCounter(0, onu.intfId, onu.onuId, MacBridgePMHistoryData, 0x0201, MacBridgePmLearningEntryDiscardCount, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	mask := attributeMaskBit(MacBridgePmLearningEntryDiscardCount)
+	resp := onu.send(GetCurrentData, MacBridgePMHistoryData, 0x0201, []byte{byte(mask >> 8), byte(mask)})
+	if discarded := binary.BigEndian.Uint32(resp[getAttributesStart:]); discarded != 7 {
+		t.Errorf("Current interval discarded learning entries are %d, expected 7", discarded)
+	}
+	// The last completed interval is read once the current one is over
+	if discarded := binary.BigEndian.Uint32(onu.mustGet(MacBridgePMHistoryData, 0x0201, mask)); discarded != 0 {
+		t.Errorf("Discarded learning entries are %d before the rollover, expected 0", discarded)
+	}
+	if err := RolloverPmIntervals(0, onu.intfId, onu.onuId); err != nil {
+		t.Fatal(err)
+	}
+	if discarded := binary.BigEndian.Uint32(onu.mustGet(MacBridgePMHistoryData, 0x0201, mask)); discarded != 7 {
+		t.Errorf("Last interval discarded learning entries are %d, expected 7", discarded)
+	}
+}
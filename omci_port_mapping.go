@@ -0,0 +1,75 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// Port Mapping Package attribute numbers
+const (
+	PortMappingMaxPorts = iota + 1
+	PortMappingPortList1
+	PortMappingPortList2
+	PortMappingPortList3
+	PortMappingPortList4
+	PortMappingPortList5
+	PortMappingPortList6
+	PortMappingPortList7
+	PortMappingPortList8
+	PortMappingCombinedPortTable
+)
+
+// portListLength is the number of ports described by each port list of the Port Mapping Package
+const portListLength = 16
+
+// defaultPortTypes are the physical ports of the ONU unless Config.PortTypes is set, the ones of the
+// Ethernet circuit pack
+var defaultPortTypes = []uint8{0x2f, 0x2f, 0x2f, 0x2f}
+
+// The Port Mapping Package of the ONU is created by the ONU, it describes the ports in Config.PortTypes
+func init() {
+	portList := AttributeDefinition{Size: portListLength, Access: AttrRead}
+	attributes := []AttributeDefinition{{Name: "MaxPorts", Size: 1, Access: AttrRead}}
+	for _, name := range []string{"PortList1", "PortList2", "PortList3", "PortList4", "PortList5", "PortList6",
+		"PortList7", "PortList8"} {
+		portList.Name = name
+		attributes = append(attributes, portList)
+	}
+	attributes = append(attributes, AttributeDefinition{Name: "CombinedPortTable", Size: 25, Access: AttrRead, Table: true})
+
+	MeDefinitions[PortMappingPackage] = &MeDefinition{
+		Name:       "PortMappingPackage",
+		Attributes: attributes,
+		Instances: func() []uint16 {
+			return []uint16{0}
+		},
+		Init: initPortMappingPackage,
+	}
+}
+
+func initPortMappingPackage(_ OnuKey, _ uint16, attrs MeAttributes) {
+	ports := Config.PortTypes
+	if ports == nil {
+		ports = defaultPortTypes
+	}
+	// The port lists describe up to 128 ports
+	if len(ports) > 8*portListLength {
+		ports = ports[:8*portListLength]
+	}
+
+	attrs[PortMappingMaxPorts][0] = uint8(len(ports))
+	for i, portType := range ports {
+		attrs[PortMappingPortList1+i/portListLength][i%portListLength] = portType
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestPortMappingMaxPorts(t *testing.T) {
+	onu := newTestOnu(t)
+	mask := attributeMaskBit(PortMappingMaxPorts) | attributeMaskBit(PortMappingPortList1)
+	values := onu.mustGet(PortMappingPackage, 0, mask)
+	if values[0] != uint8(len(defaultPortTypes)) {
+		t.Errorf("Max ports is %d, expected %d", values[0], len(defaultPortTypes))
+	}
+	if ports := values[1 : 1+len(defaultPortTypes)]; !bytes.Equal(ports, defaultPortTypes) {
+		t.Errorf("Port list is %x, expected %x", ports, defaultPortTypes)
+	}
+
+	// The Port Mapping Package is reported in the MIB upload
+	onu.startMibUpload()
+	resp, err := onu.mibUploadNext(291)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if class, maxPorts := OmciClass(binary.BigEndian.Uint16(resp[8:10])), resp[mibUploadValuesStart]; class != PortMappingPackage || maxPorts != values[0] {
+		t.Errorf("MibUploadNext 291 reports class %d with max ports %d, expected %d with %d", class, maxPorts, PortMappingPackage, values[0])
+	}
+}
+
+func TestPortMappingConfiguredPorts(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	// 20 Ethernet ports and a POTS port, the last ones in the second port list
+	Config.PortTypes = append(bytes.Repeat([]byte{0x2f}, 20), 0x20)
+	onu := newTestOnu(t)
+
+	if maxPorts := onu.mustGet(PortMappingPackage, 0, attributeMaskBit(PortMappingMaxPorts))[0]; maxPorts != 21 {
+		t.Errorf("Max ports is %d, expected 21", maxPorts)
+	}
+	ports := onu.mustGet(PortMappingPackage, 0, attributeMaskBit(PortMappingPortList2))[:portListLength]
+	expected := append(bytes.Repeat([]byte{0x2f}, 4), 0x20)
+	if !bytes.Equal(ports[:len(expected)], expected) {
+		t.Errorf("Second port list is %x, expected %x", ports, expected)
+	}
+}
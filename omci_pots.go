@@ -0,0 +1,113 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PPTP POTS UNI attribute numbers
+const (
+	PotsUniAdministrativeState = iota + 1
+	PotsUniInterworkingTpPointer
+	PotsUniArc
+	PotsUniArcInterval
+	PotsUniImpedance
+	PotsUniTransmissionPath
+	PotsUniRxGain
+	PotsUniTxGain
+	PotsUniOperationalState
+	PotsUniHookState
+	PotsUniHoldoverTime
+	PotsUniNominalFeedVoltage
+	PotsUniLossOfSoftswitch
+)
+
+const (
+	PotsUniOnHook  uint8 = 0x00
+	PotsUniOffHook uint8 = 0x01
+)
+
+func init() {
+	MeDefinitions[PPTPPotsUNI] = &MeDefinition{
+		Name: "PhysicalPathTerminationPointPotsUni",
+		Attributes: []AttributeDefinition{
+			{Name: "AdministrativeState", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "InterworkingTpPointer", Size: 2, Access: AttrRead | AttrWrite},
+			{Name: "Arc", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "ArcInterval", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "Impedance", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "TransmissionPath", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "RxGain", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "TxGain", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "OperationalState", Size: 1, Access: AttrRead},
+			{Name: "HookState", Size: 1, Access: AttrRead},
+			{Name: "PotsHoldoverTime", Size: 2, Access: AttrRead | AttrWrite},
+			{Name: "NominalFeedVoltage", Size: 1, Access: AttrRead | AttrWrite},
+			{Name: "LossOfSoftswitch", Size: 1, Access: AttrRead | AttrWrite},
+		},
+		Validate:  validatePotsUni,
+		Instances: potsUniInstances,
+		MibUpload: true,
+	}
+}
+
+func potsUniInstances() []uint16 {
+	instances := make([]uint16, 0, Config.NumPotsUni)
+	for i := 1; i <= Config.NumPotsUni; i++ {
+		instances = append(instances, 0x0100|uint16(i))
+	}
+	return instances
+}
+
+func validatePotsUni(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	var failed uint16
+
+	// 0 unlocked, 1 locked
+	if attrs[PotsUniAdministrativeState][0] > 1 {
+		failed |= attributeMaskBit(PotsUniAdministrativeState)
+	}
+	// 600 Ohm, 900 Ohm and the three complex impedances of G.988
+	if attrs[PotsUniImpedance][0] > 4 {
+		failed |= attributeMaskBit(PotsUniImpedance)
+	}
+
+	return failed
+}
+
+// SimulatePotsHookState takes a POTS UNI of an ONU off-hook or puts it back on-hook
+func SimulatePotsHookState(oltId int, intfId uint32, onuId uint32, instance uint16, offHook bool) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+		return errors.New(errmsg)
+	}
+	attrs, ok := state.getMe(PPTPPotsUNI, instance)
+	if !ok {
+		return fmt.Errorf("POTS UNI instance %d does not exist", instance)
+	}
+
+	attrs[PotsUniHookState][0] = PotsUniOnHook
+	if offHook {
+		attrs[PotsUniHookState][0] = PotsUniOffHook
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// newTestVoiceOnu returns a freshly reset ONU with a single POTS UNI, instance 0x0101
+func newTestVoiceOnu(t *testing.T) *testOnu {
+	defer func(numPotsUni int) { Config.NumPotsUni = numPotsUni }(Config.NumPotsUni)
+	Config.NumPotsUni = 1
+	return newTestOnu(t)
+}
+
+func TestPotsUniHookState(t *testing.T) {
+	onu := newTestVoiceOnu(t)
+
+	if result, _ := onu.set(PPTPPotsUNI, 0x0101, attributeMaskBit(PotsUniAdministrativeState), 0x01); result != Success {
+		t.Fatalf("Set of the administrative state got result %d", result)
+	}
+	if values := onu.mustGet(PPTPPotsUNI, 0x0101, attributeMaskBit(PotsUniAdministrativeState)); values[0] != 0x01 {
+		t.Errorf("Administrative state is %d, expected 1", values[0])
+	}
+
+	if values := onu.mustGet(PPTPPotsUNI, 0x0101, attributeMaskBit(PotsUniHookState)); values[0] != PotsUniOnHook {
+		t.Errorf("Hook state is %d, expected on-hook", values[0])
+	}
+	if err := SimulatePotsHookState(0, onu.intfId, onu.onuId, 0x0101, true); err != nil {
+		t.Fatal(err)
+	}
+	if values := onu.mustGet(PPTPPotsUNI, 0x0101, attributeMaskBit(PotsUniHookState)); values[0] != PotsUniOffHook {
+		t.Errorf("Hook state is %d, expected off-hook", values[0])
+	}
+}
+
+func TestPotsUniInvalidImpedance(t *testing.T) {
+	onu := newTestVoiceOnu(t)
+	if result, _ := onu.set(PPTPPotsUNI, 0x0101, attributeMaskBit(PotsUniImpedance), 0x05); result == Success {
+		t.Error("Set of an invalid impedance succeeded")
+	}
+}
+
+func TestPotsUniMibUpload(t *testing.T) {
+	onu := newTestVoiceOnu(t)
+
+	// The POTS UNI is reported after the MEs every ONU has
+	if n := onu.startMibUpload(); n != numStaticMibUploads+1 {
+		t.Fatalf("The MIB upload has %d entries, expected %d", n, numStaticMibUploads+1)
+	}
+	resp, err := onu.mibUploadNext(numStaticMibUploads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if class, instance := OmciClass(binary.BigEndian.Uint16(resp[8:10])), binary.BigEndian.Uint16(resp[10:12]); class != PPTPPotsUNI || instance != 0x0101 {
+		t.Errorf("Got %s %#04x, expected the POTS UNI", class.PrettyPrint(), instance)
+	}
+	if mask := binary.BigEndian.Uint16(resp[12:14]); mask != 0xFFF8 {
+		t.Errorf("Got attribute mask %#04x, expected all the attributes", mask)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// RunDiscoveryAndProvision drives the OLT side of the ONU bring-up against the simulator: MIB reset
+// and upload, capability Gets, provisioning of a T-CONT and a GEM port, and a MIB data sync audit.
+// It returns an error naming the first step which did not succeed.
+func RunDiscoveryAndProvision(oltId int, intfId uint32, onuId uint32) error {
+	var txId uint16
+	next := func() uint16 {
+		txId++
+		return txId
+	}
+
+	if _, err := runStep(oltId, intfId, onuId, "MibReset", EncodeRequest(next(), MibReset, ONUData, 0, nil)); err != nil {
+		return err
+	}
+	resp, err := runStep(oltId, intfId, onuId, "MibUpload", EncodeRequest(next(), MibUpload, ONUData, 0, nil))
+	if err != nil {
+		return err
+	}
+	numUploads := binary.BigEndian.Uint16(resp[8:10])
+	for i := uint16(0); i < numUploads; i++ {
+		commandNumber := []byte{byte(i >> 8), byte(i)}
+		request := EncodeRequest(next(), MibUploadNext, ONUData, 0, commandNumber)
+		if _, err := runStep(oltId, intfId, onuId, fmt.Sprintf("MibUploadNext %d", i), request); err != nil {
+			return err
+		}
+	}
+
+	capabilities := [][]byte{
+		// The vendor id, version and serial number don't fit in a single Get response
+		EncodeRequest(next(), Get, ONUG, 0, []byte{0xa0, 0x00}),
+		EncodeRequest(next(), Get, ONUG, 0, []byte{0x40, 0x00}),
+		EncodeRequest(next(), Get, ONU2G, 0, []byte{0xe0, 0x00}),
+		EncodeRequest(next(), Get, ANIG, 0x8001, []byte{0xc0, 0x00}),
+		EncodeRequest(next(), Get, OMCI, 0, []byte{0xc0, 0x00}),
+	}
+	if err := runSequenceStep(oltId, intfId, onuId, "capability Get", capabilities); err != nil {
+		return err
+	}
+
+	// The T-CONT and priority queues are the first ones reported in the MIB upload
+	allocId := uint16(1024 + onuId)
+	provisioning := [][]byte{
+		EncodeRequest(next(), Set, TCONT, 0x8001, []byte{0x80, 0x00, byte(allocId >> 8), byte(allocId)}),
+		EncodeCreate(next(), GEMPortNetworkCTP, 1, map[int][]byte{
+			GemPortCtpPortId:                           {byte(allocId >> 8), byte(allocId)},
+			GemPortCtpTcontPointer:                     {0x80, 0x01},
+			GemPortCtpDirection:                        {GemPortBidirectional},
+			GemPortCtpTrafficManagementPointerUpstream: {0x80, 0x01},
+			GemPortCtpPriorityQueuePointerDownstream:   {0x00, 0x01},
+		}),
+	}
+	if err := runSequenceStep(oltId, intfId, onuId, "provisioning", provisioning); err != nil {
+		return err
+	}
+
+	audit := [][]byte{EncodeRequest(next(), Get, ONUData, 0, []byte{0x80, 0x00})}
+	return runSequenceStep(oltId, intfId, onuId, "MIB data sync audit", audit)
+}
+
+func runStep(oltId int, intfId uint32, onuId uint32, step string, request []byte) ([]byte, error) {
+	resp, err := OmciSim(oltId, intfId, onuId, request)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %s", step, err)
+	}
+	if len(resp) < BaselineFrameLength {
+		return nil, fmt.Errorf("%s got no response", step)
+	}
+	if result := ResponseResult(resp); result != Success {
+		return nil, fmt.Errorf("%s failed with result %d", step, result)
+	}
+	return resp, nil
+}
+
+func runSequenceStep(oltId int, intfId uint32, onuId uint32, step string, requests [][]byte) error {
+	results, err := RunSequence(oltId, intfId, onuId, requests)
+	if err != nil {
+		return fmt.Errorf("%s failed: %s", step, err)
+	}
+	for i, result := range results {
+		if result != Success {
+			return fmt.Errorf("%s %d failed with result %d", step, i, result)
+		}
+	}
+	return nil
+}
+
+func TestRunDiscoveryAndProvision(t *testing.T) {
+	intfId := atomic.AddUint32(&testIntfId, 1)
+	defer drainChannel()
+
+	if IsProvisioningComplete(0, intfId, 1) {
+		t.Fatal("Provisioning is complete before the discovery")
+	}
+	if err := RunDiscoveryAndProvision(0, intfId, 1); err != nil {
+		t.Fatalf("Discovery and provisioning failed: %s", err)
+	}
+	if !IsProvisioningComplete(0, intfId, 1) {
+		t.Error("Provisioning is not complete after the discovery")
+	}
+
+	// The MibReset of a second run starts from a clean ONU
+	if err := RunDiscoveryAndProvision(0, intfId, 1); err != nil {
+		t.Errorf("Second discovery and provisioning failed: %s", err)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// Pseudowire Maintenance Profile attribute numbers
+const (
+	PwMaintenanceJitterBufferMaximumDepth = iota + 1
+	PwMaintenanceJitterBufferDesiredDepth
+	PwMaintenanceFillPolicy
+	PwMaintenanceMisconnectedPacketsDeclarationPolicy
+	PwMaintenanceMisconnectedPacketsClearPolicy
+	PwMaintenanceLossOfPacketsDeclarationPolicy
+	PwMaintenanceLossOfPacketsClearPolicy
+	PwMaintenanceBufferOverrunUnderrunDeclarationPolicy
+	PwMaintenanceBufferOverrunUnderrunClearPolicy
+	PwMaintenanceMalformedPacketsDeclarationPolicy
+	PwMaintenanceMalformedPacketsClearPolicy
+	PwMaintenanceRBitTransmitSetPolicy
+	PwMaintenanceRBitReceivePolicy
+	PwMaintenanceLBitReceivePolicy
+	PwMaintenanceSesThreshold
+)
+
+func init() {
+	// The jitter buffer depths are in units of 125 us
+	MeDefinitions[PseudowireMaintenance] = &MeDefinition{
+		Name: "PseudowireMaintenanceProfile",
+		Attributes: []AttributeDefinition{
+			{Name: "JitterBufferMaximumDepth", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "JitterBufferDesiredDepth", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "FillPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MisconnectedPacketsDeclarationPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MisconnectedPacketsClearPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "LossOfPacketsDeclarationPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "LossOfPacketsClearPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "BufferOverrunUnderrunDeclarationPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "BufferOverrunUnderrunClearPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MalformedPacketsDeclarationPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MalformedPacketsClearPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "RBitTransmitSetPolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "RBitReceivePolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "LBitReceivePolicy", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "SesThreshold", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+		Validate: validatePseudowireMaintenance,
+	}
+}
+
+// validatePseudowireMaintenance checks that the desired jitter buffer depth fits in the
+// maximum one, zero selecting the ONU default for either
+func validatePseudowireMaintenance(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	max := attrs.uint16(PwMaintenanceJitterBufferMaximumDepth)
+	desired := attrs.uint16(PwMaintenanceJitterBufferDesiredDepth)
+	if max != 0 && desired > max {
+		return attributeMaskBit(PwMaintenanceJitterBufferDesiredDepth)
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestPseudowireMaintenanceJitterBuffer(t *testing.T) {
+	onu := newTestOnu(t)
+
+	// 8 ms maximum and 4 ms desired depths
+	onu.mustCreate(PseudowireMaintenance, 1, map[int][]byte{
+		PwMaintenanceJitterBufferMaximumDepth: {0x00, 0x40},
+		PwMaintenanceJitterBufferDesiredDepth: {0x00, 0x20},
+	})
+	mask := attributeMaskBit(PwMaintenanceJitterBufferMaximumDepth) | attributeMaskBit(PwMaintenanceJitterBufferDesiredDepth)
+	values := onu.mustGet(PseudowireMaintenance, 1, mask)
+	if max, desired := binary.BigEndian.Uint16(values), binary.BigEndian.Uint16(values[2:]); max != 0x40 || desired != 0x20 {
+		t.Errorf("Jitter buffer depths are %#04x and %#04x, expected 0x0040 and 0x0020", max, desired)
+	}
+
+	// The desired depth doesn't fit in the maximum one
+	if result, _ := onu.set(PseudowireMaintenance, 1, attributeMaskBit(PwMaintenanceJitterBufferDesiredDepth), 0x00, 0x80); result != ParameterError {
+		t.Errorf("Set of a desired depth above the maximum got result %d, expected %d", result, ParameterError)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// Dot1 Rate Limiter attribute numbers
+const (
+	Dot1RateLimiterParentMePointer = iota + 1
+	Dot1RateLimiterTpType
+	Dot1RateLimiterUpstreamUnicastFloodRatePointer
+	Dot1RateLimiterUpstreamBroadcastRatePointer
+	Dot1RateLimiterUpstreamMulticastPayloadRatePointer
+)
+
+// dot1RateLimiterParents are the ME classes a Dot1 Rate Limiter can be attached to, indexed by TP type
+var dot1RateLimiterParents = map[uint8]OmciClass{
+	1: MacBridgeServiceProfile,
+	2: Ieee8021pMapperServiceProfile,
+}
+
+func init() {
+	MeDefinitions[Dot1RateLimiter] = &MeDefinition{
+		Name: "Dot1RateLimiter",
+		Attributes: []AttributeDefinition{
+			{Name: "ParentMePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "TpType", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "UpstreamUnicastFloodRatePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "UpstreamBroadcastRatePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "UpstreamMulticastPayloadRatePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+		Validate: validateDot1RateLimiter,
+	}
+}
+
+// validateDot1RateLimiter checks the TP type and that the rate limiter has a parent, which must
+// exist when it is one of the MEs in MeDefinitions
+func validateDot1RateLimiter(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	class, ok := dot1RateLimiterParents[attrs[Dot1RateLimiterTpType][0]]
+	if !ok {
+		return attributeMaskBit(Dot1RateLimiterTpType)
+	}
+
+	parent := attrs.uint16(Dot1RateLimiterParentMePointer)
+	if isNullPointer(parent) {
+		return attributeMaskBit(Dot1RateLimiterParentMePointer)
+	}
+	if _, ok := MeDefinitions[class]; ok {
+		if _, ok := state.getMe(class, parent); !ok {
+			return attributeMaskBit(Dot1RateLimiterParentMePointer)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDot1RateLimiter(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(MacBridgeServiceProfile, 0x0201, nil)
+	onu.mustCreate(Dot1RateLimiter, 0x0001, map[int][]byte{
+		Dot1RateLimiterParentMePointer:                     {0x02, 0x01},
+		Dot1RateLimiterTpType:                              {0x01},
+		Dot1RateLimiterUpstreamUnicastFloodRatePointer:     {0x00, 0x11},
+		Dot1RateLimiterUpstreamBroadcastRatePointer:        {0x00, 0x12},
+		Dot1RateLimiterUpstreamMulticastPayloadRatePointer: {0x00, 0x13},
+	})
+
+	values := onu.mustGet(Dot1RateLimiter, 0x0001, 0xf800)
+	expected := []byte{0x02, 0x01, 0x01, 0x00, 0x11, 0x00, 0x12, 0x00, 0x13}
+	if !bytes.Equal(values[:len(expected)], expected) {
+		t.Errorf("Attributes are %x, expected %x", values[:len(expected)], expected)
+	}
+}
+
+func TestDot1RateLimiterMissingParent(t *testing.T) {
+	onu := newTestOnu(t)
+	result := onu.create(Dot1RateLimiter, 0x0001, map[int][]byte{
+		Dot1RateLimiterParentMePointer: {0x02, 0x01},
+		Dot1RateLimiterTpType:          {0x01},
+	})
+	if result != ParameterError {
+		t.Errorf("Create got result %d, expected %d", result, ParameterError)
+	}
+}
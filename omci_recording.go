@@ -0,0 +1,93 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// A record is the request followed by the response of an OmciSim call, each one prefixed by
+// its length as a 4 bytes big-endian value. A request without a response has an empty one.
+const recordLengthSize = 4
+
+var recordingWriter io.Writer
+var recordingLock = sync.Mutex{}
+
+// StartRecording writes a record of every request processed by OmciSim, and of its response, to w
+func StartRecording(w io.Writer) error {
+	recordingLock.Lock()
+	defer recordingLock.Unlock()
+	if recordingWriter != nil {
+		return errors.New("Recording already started")
+	}
+	recordingWriter = w
+	return nil
+}
+
+// StopRecording stops writing the records started by StartRecording
+func StopRecording() {
+	recordingLock.Lock()
+	defer recordingLock.Unlock()
+	recordingWriter = nil
+}
+
+// record writes the record of an OmciSim call if recording, recording stops on a write error
+func record(request []byte, response []byte) {
+	recordingLock.Lock()
+	defer recordingLock.Unlock()
+	if recordingWriter == nil {
+		return
+	}
+
+	var buf []byte
+	for _, frame := range [][]byte{request, response} {
+		length := make([]byte, recordLengthSize)
+		binary.BigEndian.PutUint32(length, uint32(len(frame)))
+		buf = append(append(buf, length...), frame...)
+	}
+	if _, err := recordingWriter.Write(buf); err != nil {
+		log.Errorf("Stopping the recording, error: %s", err)
+		recordingWriter = nil
+	}
+}
+
+// ReadRecord reads the next record written by StartRecording, it returns io.EOF once all the records are read
+func ReadRecord(r io.Reader) (request []byte, response []byte, err error) {
+	frames := make([][]byte, 2)
+	for i := range frames {
+		length := make([]byte, recordLengthSize)
+		if _, err := io.ReadFull(r, length); err != nil {
+			if i == 1 && err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, nil, err
+		}
+		frames[i] = make([]byte, binary.BigEndian.Uint32(length))
+		if _, err := io.ReadFull(r, frames[i]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, nil, err
+		}
+	}
+	return frames[0], frames[1], nil
+}
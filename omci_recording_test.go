@@ -0,0 +1,68 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestRecording(t *testing.T) {
+	onu := newTestOnu(t)
+	var buf bytes.Buffer
+	if err := StartRecording(&buf); err != nil {
+		t.Fatal(err)
+	}
+	defer StopRecording()
+	if err := StartRecording(&bytes.Buffer{}); err == nil {
+		t.Error("A second StartRecording succeeded")
+	}
+
+	requests := [][]byte{
+		EncodeRequest(onu.nextTxId(), Get, ONUG, 0, []byte{0x80, 0x00}),
+		EncodeRequest(onu.nextTxId(), Set, PPTPEthernetUNI, 257, []byte{0x08, 0x00, 0x00}),
+	}
+	var responses [][]byte
+	for _, request := range requests {
+		responses = append(responses, onu.sendFrame(request))
+	}
+	StopRecording()
+	onu.send(Get, ONUG, 0, []byte{0x80, 0x00})
+
+	for i := range requests {
+		request, response, err := ReadRecord(&buf)
+		if err != nil {
+			t.Fatalf("Record %d: %s", i, err)
+		}
+		if !bytes.Equal(request, requests[i]) || !bytes.Equal(response, responses[i]) {
+			t.Errorf("Record %d is %x / %x, expected %x / %x", i, request, response, requests[i], responses[i])
+		}
+	}
+	// Nothing is recorded after StopRecording
+	if _, _, err := ReadRecord(&buf); err != io.EOF {
+		t.Errorf("Reading past the last record got %v, expected %v", err, io.EOF)
+	}
+}
+
+func TestReadTruncatedRecord(t *testing.T) {
+	// A request of 4 bytes without its response
+	r := bytes.NewReader([]byte{0x00, 0x00, 0x00, 0x04, 0x01, 0x02, 0x03, 0x04})
+	if _, _, err := ReadRecord(r); err != io.ErrUnexpectedEOF {
+		t.Errorf("Reading a truncated record got %v, expected %v", err, io.ErrUnexpectedEOF)
+	}
+}
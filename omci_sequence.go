@@ -0,0 +1,67 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+)
+
+// ResponseResult returns the result reason of an OMCI response, responses
+// without a result reason (MIB upload and alarm retrieval) are successful and
+// responses too short to carry one are reported as a processing error
+func ResponseResult(resp []byte) OmciResult {
+	if len(resp) < 4 {
+		return ProcessingError
+	}
+	switch OmciMsgType(resp[2] & 0x1F) {
+	case MibUpload, MibUploadNext, GetAllAlarms, GetAllAlarmsNext:
+		return Success
+	default:
+		offset := 8
+		if isExtendedFrame(resp) {
+			offset = ExtendedHeaderLength
+		}
+		if len(resp) <= offset {
+			return ProcessingError
+		}
+		return OmciResult(resp[offset])
+	}
+}
+
+// isExtendedFrame reports whether a frame has the extended layout, from its device identifier
+func isExtendedFrame(frame []byte) bool {
+	return len(frame) >= ExtendedHeaderLength && frame[3] == ExtendedDeviceId
+}
+
+// RunSequence processes a recorded sequence of OMCI requests for an ONU and returns the
+// result reason of each response, it stops at the first request not getting a response
+func RunSequence(oltId int, intfId uint32, onuId uint32, requests [][]byte) ([]OmciResult, error) {
+	results := make([]OmciResult, 0, len(requests))
+
+	for i, request := range requests {
+		resp, err := OmciSim(oltId, intfId, onuId, request)
+		if err != nil {
+			return results, fmt.Errorf("Request %d failed: %s", i, err)
+		}
+		if len(resp) < 9 {
+			return results, fmt.Errorf("Request %d got no response", i)
+		}
+		results = append(results, ResponseResult(resp))
+	}
+
+	return results, nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+)
+
+func TestRunSequence(t *testing.T) {
+	onu := newTestOnu(t)
+	// Service setup: T-CONT, GEM port, MAC bridge and its ANI side port
+	requests := [][]byte{
+		EncodeRequest(1, Set, TCONT, 0x8001, []byte{0x80, 0x00, 0x04, 0x00}),
+		EncodeCreate(2, GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401)),
+		EncodeCreate(3, MacBridgeServiceProfile, 0x0201, nil),
+		EncodeCreate(4, MacBridgePortConfigData, 0x2102, map[int][]byte{
+			MacBridgePortBridgeIdPointer: {0x02, 0x01},
+			MacBridgePortTpType:          {0x05},
+			MacBridgePortTpPointer:       {0x04, 0x01},
+		}),
+		EncodeRequest(5, Get, ONUData, 0, []byte{0x80, 0x00}),
+	}
+
+	results, err := RunSequence(0, onu.intfId, onu.onuId, requests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(requests) {
+		t.Fatalf("Got %d results, expected %d", len(results), len(requests))
+	}
+	for i, result := range results {
+		if result != Success {
+			t.Errorf("Request %d got result %d", i, result)
+		}
+	}
+}
+
+func TestResponseResult(t *testing.T) {
+	onu := newTestOnu(t)
+
+	resp := onu.send(Get, GEMPortNetworkCTP, 0x0999, []byte{0x80, 0x00})
+	if result := ResponseResult(resp); result != UnknownInstance {
+		t.Errorf("Got result %d, expected %d", result, UnknownInstance)
+	}
+
+	resp = onu.sendFrame(EncodeExtendedRequest(onu.nextTxId(), Get, GEMPortNetworkCTP, 0x0999, []byte{0x80, 0x00}))
+	if result := ResponseResult(resp); result != UnknownInstance {
+		t.Errorf("Got extended result %d, expected %d", result, UnknownInstance)
+	}
+
+	for _, short := range [][]byte{nil, {0x00, 0x01, 0x29}, resp[:8], resp[:ExtendedHeaderLength]} {
+		if result := ResponseResult(short); result != ProcessingError {
+			t.Errorf("Got result %d for %x, expected %d", result, short, ProcessingError)
+		}
+	}
+}
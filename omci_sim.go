@@ -27,7 +27,38 @@ func GetChannel() chan OmciChMessage {
 	return omciCh
 }
 
+// messageHeaderLength is the length of the header OmciSim fills in, up to the ME instance
+const messageHeaderLength = 8
+
+// pptpEthernetUniOperationalState is the attribute of the PPTP Ethernet UNI following its administrative state
+const pptpEthernetUniOperationalState = 6
+
+// pptpEthernetUniAdministrativeState is the attribute of the PPTP Ethernet UNI locking and unlocking it
+const pptpEthernetUniAdministrativeState = 5
+
+// pptpAdministrativeState returns the administrative state written by the contents of a Set of a PPTP
+// Ethernet UNI, if any: the attributes before it are all 1 byte long
+func pptpAdministrativeState(content OmciContent) (byte, bool) {
+	mask := uint16(content[0])<<8 | uint16(content[1])
+	if mask&attributeMaskBit(pptpEthernetUniAdministrativeState) == 0 {
+		return 0, false
+	}
+	pos := 2
+	for index := 1; index < pptpEthernetUniAdministrativeState; index++ {
+		if mask&attributeMaskBit(index) != 0 {
+			pos++
+		}
+	}
+	return content[pos], true
+}
+
 func OmciSim(oltId int, intfId uint32, onuId uint32, request []byte) ([]byte, error) {
+	resp, err := processRequest(oltId, intfId, onuId, request)
+	record(request, resp)
+	return resp, err
+}
+
+func processRequest(oltId int, intfId uint32, onuId uint32, request []byte) ([]byte, error) {
 	var resp []byte
 
 	transactionId, deviceId, msgType, class, instance, content, err := ParsePkt(request)
@@ -38,144 +69,156 @@ func OmciSim(oltId int, intfId uint32, onuId uint32, request []byte) ([]byte, er
 		}).Errorf("Cannot parse OMCI msg")
 		return resp, &OmciError{"Cannot parse OMCI msg"}
 	}
-
-	log.WithFields(log.Fields{
+	key := OnuKey{OltId: oltId, IntfId: intfId, OnuId: onuId}
+	corrId := correlationId(key, transactionId)
+	// Every log entry of the request carries its correlation id
+	logger := log.WithFields(log.Fields{
 		"IntfId": intfId,
 		"OnuId": onuId,
-		"TransactionId": transactionId,
-		"MessageType": msgType.PrettyPrint(),
-		"MeClass": class,
-		"MeInstance": instance,
-		//"Conent": content,
-		"omciMsg": fmt.Sprintf("%x", content),
-	}).Tracef("Processing OMCI packet")
+		"CorrelationId": corrId,
+	})
+	// A response with transaction id 0 may be taken for an autonomous message
+	if transactionId == AutonomousTransactionId && Config.DropZeroTransactionId {
+		logger.WithFields(log.Fields{
+			"msgType": msgType,
+		}).Warnf("Dropping omci msg with the transaction id reserved for autonomous messages")
+		return resp, &OmciError{"Transaction id 0 is reserved for autonomous messages"}
+	} else if transactionId == AutonomousTransactionId {
+		logger.WithFields(log.Fields{
+			"msgType": msgType,
+		}).Warnf("Answering omci msg with the transaction id reserved for autonomous messages")
+	}
 
-	key := OnuKey{OltId: oltId, IntfId: intfId, OnuId: onuId}
 	OnuOmciStateMapLock.Lock()
 	if _, ok := OnuOmciStateMap[key]; !ok {
-		OnuOmciStateMap[key] = NewOnuOmciState()
+		OnuOmciStateMap[key] = newOnuOmciState(key)
 	}
 	OnuOmciStateMapLock.Unlock()
 
 	if _, ok := Handlers[msgType]; !ok {
-		log.WithFields(log.Fields{
-			"IntfId": intfId,
-			"OnuId": onuId,
+		logger.WithFields(log.Fields{
 			"msgType": msgType,
 		}).Errorf("Ignoring omci msg (msgType %d not handled)", msgType)
 		return resp, &OmciError{"Unimplemented omci msg"}
 	}
 
-	resp, err = Handlers[msgType](class, content, key)
+	ctx := &HandlerContext{Key: key, TransactionId: transactionId, DeviceId: deviceId, MsgType: msgType,
+		Class: class, Instance: instance, Content: content, CorrelationId: corrId, Logger: logger}
+	resp, err = requestHandler()(ctx)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"IntfId": intfId,
-			"OnuId": onuId,
-			"msgType": msgType,
-		}).Errorf("Unable to send a successful response, error: %s", err)
 		return resp, nil
 	}
+	// A middleware may answer with a response too short for its header
+	if len(resp) < messageHeaderLength {
+		logger.WithFields(log.Fields{
+			"msgType": msgType,
+		}).Errorf("Ignoring omci response of %d bytes, too short for its header", len(resp))
+		return nil, &OmciError{"Invalid omci response length"}
+	}
 
 	// In the OMCI message, first 2-bytes is the Transaction Correlation ID
 	resp[0] = byte(transactionId >> 8)
 	resp[1] = byte(transactionId & 0xFF)
 	resp[2] = 0x2<<4 | byte(msgType) // Upper nibble 0x2 is fixed (0010), Lower nibbles defines the msg type (i.e., mib-upload, mib-upload-next, etc)
 	resp[3] = deviceId
+	if Config.ResponseDeviceId != 0 {
+		resp[3] = Config.ResponseDeviceId
+	}
 
 	// for create, get and set
 	if ((msgType & 0xFF) != MibUploadNext) && ((msgType & 0xFF) != MibReset) && ((msgType & 0xFF) != MibUpload) {
 		// Common fields for create, get, and set
 		resp[4] = byte(class >> 8)
 		resp[5] = byte(class & 0xFF)
-		resp[6] = byte(instance >> 8)
-		resp[7] = byte(instance & 0xFF)
-		resp[8] = 0 // Result: Command Processed Successfully
-
-		// Hardcoding class specific values for Get
-		if (class == 0x82) && ((msgType & 0x0F) == Get) {
-			resp[9] = 0
-			resp[10] = 0x78
-
-		} else if (class == 0x2F) && ((msgType & 0x0F) == Get) {
-			resp[9] = 0x0F
-			resp[10] = 0xB8
-		} else if (class == 0x138) && ((msgType & 0x0F) == Get) {
-			resp[9] = content[0] // 0xBE
-			resp[10] = 0x00
+		// A Get of the wildcard instance keeps the instance it was answered with
+		if msgType != Get || instance != WildcardInstance {
+			resp[6] = byte(instance >> 8)
+			resp[7] = byte(instance & 0xFF)
+		}
+
+		// Hardcoding class specific values for a successful Get
+		if deviceId == BaselineDeviceId && len(resp) == BaselineFrameLength && resp[8] == byte(Success) {
+			if (class == 0x82) && ((msgType & 0x0F) == Get) {
+				resp[9] = 0
+				resp[10] = 0x78
+			}
 		}
 	}
 
-	if (class == 11 && instance == 257 && msgType == Set) {
-		// This is a set on a PPTP instance 257 (lan port 1)
+	if class == PPTPEthernetUNI && msgType == Set && len(resp) > 8 && resp[8] == byte(Success) {
+		// This is a successful set on a PPTP, a rejected one (e.g. with the provisioning lock) leaves
+		// the UNI as it was
 		// Determine if its setting admin up or down and alarm appropriately
-
-		// attrmask for what specifically sets/gets is 2 bytes
-		attrmask := content[0:2]
-		// actual content is the rest, as specified by attrmask.  but for this case we are only interested in 1 byte
-		firstattr := content[2:3]
+		adminState, ok := pptpAdministrativeState(content)
 
 		// attribute bit 5 (admin state) in the PPTP is being set, its value is 1, lock
-		if (attrmask[0] == 0x08 && firstattr[0] == 0x01) {
-			log.Info("Send UNI Link Down Alarm on OMCI Sim channel")
-
-			linkMsgDown := []byte{
-				0x00, 0x00, 0x10, 0x0a, 0x00, 0x0b, 0x01, 0x01,
-				0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
-
-			msg := OmciChMessage{
-				Type: UniLinkDown,
-				Data: OmciChMessageData{
-					OnuId:  key.OnuId,
-					IntfId: key.IntfId,
-				},
-				Packet: linkMsgDown,
-			}
-			omciCh <- msg
-
-			OnuOmciStateMapLock.Lock()
-			if OnuOmciState, ok := OnuOmciStateMap[key]; ok {
-				OnuOmciState.state = LOCKED
+		if ok && adminState == 0x01 {
+			if instance == 257 {
+				// The UNI link alarms are those of the lan port 1
+				log.Info("Send UNI Link Down Alarm on OMCI Sim channel")
+
+				linkMsgDown := []byte{
+					0x00, 0x00, 0x10, 0x0a, 0x00, 0x0b, 0x01, 0x01,
+					0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+				msg := OmciChMessage{
+					Type: UniLinkDown,
+					Data: OmciChMessageData{
+						OnuId:  key.OnuId,
+						IntfId: key.IntfId,
+					},
+					Packet: linkMsgDown,
+				}
+				omciCh <- msg
+
+				OnuOmciStateMapLock.Lock()
+				if OnuOmciState, ok := OnuOmciStateMap[key]; ok {
+					OnuOmciState.state = LOCKED
+				}
+				OnuOmciStateMapLock.Unlock()
 			}
-			OnuOmciStateMapLock.Unlock()
+			// Locking the UNI disables it
+			sendAttributeValueChange(key, class, instance, pptpEthernetUniOperationalState, []byte{0x01})
 		}
 
 		// attribute bit 5 (admin state) in the PPTP is being set, its value is 0, unlock
-		if (attrmask[0] == 0x08 && firstattr[0] == 0x00) {
-			log.Info("Send UNI Link Up Alarm on OMCI Sim channel")
-
-			linkMsgUp := []byte{
-				0x00, 0x00, 0x10, 0x0a, 0x00, 0x0b, 0x01, 0x01,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
-
-			msg := OmciChMessage{
-				Type: UniLinkUp,
-				Data: OmciChMessageData{
-					OnuId:  key.OnuId,
-					IntfId: key.IntfId,
-				},
-				Packet: linkMsgUp,
+		if ok && adminState == 0x00 {
+			if instance == 257 {
+				log.Info("Send UNI Link Up Alarm on OMCI Sim channel")
+
+				linkMsgUp := []byte{
+					0x00, 0x00, 0x10, 0x0a, 0x00, 0x0b, 0x01, 0x01,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+				msg := OmciChMessage{
+					Type: UniLinkUp,
+					Data: OmciChMessageData{
+						OnuId:  key.OnuId,
+						IntfId: key.IntfId,
+					},
+					Packet: linkMsgUp,
+				}
+				omciCh <- msg
+
+				OnuOmciStateMapLock.Lock()
+				if OnuOmciState, ok := OnuOmciStateMap[key]; ok {
+					OnuOmciState.state = DONE
+				}
+				OnuOmciStateMapLock.Unlock()
 			}
-			omciCh <- msg
-
-			OnuOmciStateMapLock.Lock()
-			if OnuOmciState, ok := OnuOmciStateMap[key]; ok {
-				OnuOmciState.state = DONE
-			}
-			OnuOmciStateMapLock.Unlock()
+			sendAttributeValueChange(key, class, instance, pptpEthernetUniOperationalState, []byte{0x00})
 		}
 	}
 
-	log.WithFields(log.Fields{
-		"IntfId": intfId,
-		"OnuId": onuId,
+	logger.WithFields(log.Fields{
 		"msgType": msgType.PrettyPrint(),
 		"omciMsg": fmt.Sprintf("%x", resp),
 	}).Tracef("OMCI-SIM Response")
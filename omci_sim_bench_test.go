@@ -0,0 +1,100 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"sync/atomic"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// benchmarkRequests returns a mix of the requests an OLT sends while provisioning and auditing an ONU,
+// the GEM port created and deleted is distinct for each goroutine
+func benchmarkRequests(gemPort uint16) [][]byte {
+	null := []byte{0xFF, 0xFF}
+	return [][]byte{
+		EncodeRequest(1, Get, ONUG, 0, []byte{0x80, 0x00}),
+		EncodeRequest(2, Get, ONU2G, 0, []byte{0x80, 0x00}),
+		EncodeRequest(3, Get, ONUData, 0, []byte{0x80, 0x00}),
+		EncodeCreate(4, GEMPortNetworkCTP, gemPort, map[int][]byte{
+			GemPortCtpPortId:                             {byte(gemPort >> 8), byte(gemPort)},
+			GemPortCtpTcontPointer:                       {0x80, 0x01},
+			GemPortCtpDirection:                          {0x03},
+			GemPortCtpTrafficManagementPointerUpstream:   null,
+			GemPortCtpTrafficDescriptorPointerUpstream:   null,
+			GemPortCtpPriorityQueuePointerDownstream:     null,
+			GemPortCtpTrafficDescriptorPointerDownstream: null,
+		}),
+		EncodeRequest(5, Set, GEMPortNetworkCTP, gemPort, []byte{0x20, 0x00, 0x03}),
+		EncodeRequest(6, Get, GEMPortNetworkCTP, gemPort, []byte{0xe0, 0x00}),
+		EncodeRequest(7, Delete, GEMPortNetworkCTP, gemPort, nil),
+		EncodeRequest(8, MibUpload, ONUData, 0, nil),
+		EncodeRequest(9, MibUploadNext, ONUData, 0, []byte{0x00, 0x00}),
+		EncodeRequest(10, MibUploadNext, ONUData, 0, []byte{0x00, 0x01}),
+	}
+}
+
+// benchmarkOmciSim runs the requests of benchmarkRequests from parallel goroutines, spread over numOnus
+// ONUs. The throughput is reported in bytes of request per second.
+func benchmarkOmciSim(b *testing.B, numOnus int) {
+	level := log.GetLevel()
+	log.SetLevel(log.ErrorLevel)
+	defer log.SetLevel(level)
+
+	// Creating a GEM port notifies the ONU state machine
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-GetChannel():
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	const intfId = 200
+	for onuId := 0; onuId < numOnus; onuId++ {
+		OmciSim(0, intfId, uint32(onuId), EncodeRequest(1, MibReset, ONUData, 0, nil))
+	}
+
+	var goroutines uint32
+	b.SetBytes(BaselineFrameLength)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		n := atomic.AddUint32(&goroutines, 1)
+		onuId := n % uint32(numOnus)
+		requests := benchmarkRequests(0x0400 + uint16(n))
+		for i := 0; pb.Next(); i++ {
+			if _, err := OmciSim(0, intfId, onuId, requests[i%len(requests)]); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkOmciSimParallel(b *testing.B) {
+	benchmarkOmciSim(b, 64)
+}
+
+// BenchmarkOmciSimParallelSingleOnu measures the contention of the requests of a single ONU
+func BenchmarkOmciSimParallelSingleOnu(b *testing.B) {
+	benchmarkOmciSim(b, 1)
+}
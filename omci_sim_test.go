@@ -0,0 +1,178 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testIntfId is the interface of the last ONU created by newTestOnu, each test gets an interface
+// of its own so that its ONU starts from a clean state
+var testIntfId uint32 = 1000
+
+// testOnu sends the OMCI requests of a test to a simulated ONU
+type testOnu struct {
+	t      *testing.T
+	intfId uint32
+	onuId  uint32
+	txId   uint16
+}
+
+// newTestOnu returns a freshly reset ONU, with nothing left to read on the OMCI Sim channel
+func newTestOnu(t *testing.T) *testOnu {
+	onu := &testOnu{t: t, intfId: atomic.AddUint32(&testIntfId, 1), onuId: 1}
+	if result := onu.result(onu.send(MibReset, ONUData, 0, nil)); result != Success {
+		t.Fatalf("MibReset failed with result %d", result)
+	}
+	drainChannel()
+	return onu
+}
+
+func (o *testOnu) nextTxId() uint16 {
+	o.txId++
+	return o.txId
+}
+
+// sendFrame processes a request and fails the test if it gets no response
+func (o *testOnu) sendFrame(request []byte) []byte {
+	o.t.Helper()
+	resp, err := OmciSim(0, o.intfId, o.onuId, request)
+	if err != nil {
+		o.t.Fatalf("Request %x failed: %s", request, err)
+	}
+	return resp
+}
+
+func (o *testOnu) send(msgType OmciMsgType, class OmciClass, instance uint16, content []byte) []byte {
+	o.t.Helper()
+	return o.sendFrame(EncodeRequest(o.nextTxId(), msgType, class, instance, content))
+}
+
+func (o *testOnu) result(resp []byte) OmciResult {
+	o.t.Helper()
+	if len(resp) < BaselineFrameLength {
+		o.t.Fatalf("Short response %x", resp)
+	}
+	return ResponseResult(resp)
+}
+
+// create creates an ME and returns the result of the Create
+func (o *testOnu) create(class OmciClass, instance uint16, attrs map[int][]byte) OmciResult {
+	o.t.Helper()
+	return o.result(o.sendFrame(EncodeCreate(o.nextTxId(), class, instance, attrs)))
+}
+
+// mustCreate creates an ME and fails the test if the Create is not successful
+func (o *testOnu) mustCreate(class OmciClass, instance uint16, attrs map[int][]byte) {
+	o.t.Helper()
+	if result := o.create(class, instance, attrs); result != Success {
+		o.t.Fatalf("Create of %s %#04x failed with result %d", class.PrettyPrint(), instance, result)
+	}
+}
+
+// set writes the attributes of mask and returns the result and the attribute execution mask
+func (o *testOnu) set(class OmciClass, instance uint16, mask uint16, values ...byte) (OmciResult, uint16) {
+	o.t.Helper()
+	content := append([]byte{byte(mask >> 8), byte(mask)}, values...)
+	resp := o.send(Set, class, instance, content)
+	return o.result(resp), binary.BigEndian.Uint16(resp[11:13])
+}
+
+// get reads the attributes of mask and returns the result and the attribute values
+func (o *testOnu) get(class OmciClass, instance uint16, mask uint16) (OmciResult, []byte) {
+	o.t.Helper()
+	resp := o.send(Get, class, instance, []byte{byte(mask >> 8), byte(mask)})
+	return o.result(resp), resp[11:]
+}
+
+// mustGet reads the attributes of mask and fails the test if the Get is not successful
+func (o *testOnu) mustGet(class OmciClass, instance uint16, mask uint16) []byte {
+	o.t.Helper()
+	result, values := o.get(class, instance, mask)
+	if result != Success {
+		o.t.Fatalf("Get of %s %#04x mask %#04x failed with result %d", class.PrettyPrint(), instance, mask, result)
+	}
+	return values
+}
+
+// readTable reads a table attribute with a Get of its size followed by GetNext requests
+func (o *testOnu) readTable(class OmciClass, instance uint16, index int) []byte {
+	o.t.Helper()
+	mask := attributeMaskBit(index)
+	size := int(binary.BigEndian.Uint32(o.mustGet(class, instance, mask)))
+
+	var table []byte
+	for sequenceNumber := 0; len(table) < size; sequenceNumber++ {
+		content := []byte{byte(mask >> 8), byte(mask), byte(sequenceNumber >> 8), byte(sequenceNumber)}
+		resp := o.send(GetNext, class, instance, content)
+		if result := o.result(resp); result != Success {
+			o.t.Fatalf("GetNext %d of %s %#04x failed with result %d", sequenceNumber, class.PrettyPrint(), instance, result)
+		}
+		table = append(table, resp[getAttributesStart:getAttributesStart+getNextAttributesLength]...)
+	}
+	return table[:size]
+}
+
+// notification returns the next message of the ONU sent on the OMCI Sim channel
+func (o *testOnu) notification() OmciChMessage {
+	o.t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case msg := <-GetChannel():
+			if msg.Data.IntfId == o.intfId && msg.Data.OnuId == o.onuId {
+				return msg
+			}
+		case <-timeout:
+			o.t.Fatal("No message on the OMCI Sim channel")
+		}
+	}
+}
+
+// expectNoNotification fails the test if a message of the ONU is on the OMCI Sim channel
+func (o *testOnu) expectNoNotification() {
+	o.t.Helper()
+	for {
+		select {
+		case msg := <-GetChannel():
+			if msg.Data.IntfId == o.intfId && msg.Data.OnuId == o.onuId {
+				o.t.Fatalf("Unexpected %s %x", msg.Type, msg.Packet)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// drainChannel discards the messages waiting on the OMCI Sim channel
+func drainChannel() {
+	for {
+		select {
+		case <-GetChannel():
+		default:
+			return
+		}
+	}
+}
+
+// alarmRaised reports whether an alarm is set in the bitmap of an AlarmNotification
+func alarmRaised(pkt []byte, alarm uint) bool {
+	return pkt[8+alarm/8]&(0x80>>(alarm%8)) != 0
+}
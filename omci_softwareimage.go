@@ -28,7 +28,8 @@ const (
 	ImageHash       SoftwareImageAttributes = 0x0400
 )
 
-type SoftwareImageAttributeHandler func(*uint, []byte) ([]byte, error)
+// SoftwareImageAttributeHandler writes an attribute of the software image in the slot given by the instance
+type SoftwareImageAttributeHandler func(*uint, []byte, OnuKey, uint16) ([]byte, error)
 
 var SoftwareImageAttributeHandlers = map[SoftwareImageAttributes]SoftwareImageAttributeHandler{
 	SoftwareVersion: GetSoftwareVersion,
@@ -39,7 +40,7 @@ var SoftwareImageAttributeHandlers = map[SoftwareImageAttributes]SoftwareImageAt
 	ImageHash:       GetImageHash,
 }
 
-func GetSoftwareImageAttributes(pos *uint, pkt []byte, content OmciContent) ([]byte, error) {
+func GetSoftwareImageAttributes(pos *uint, pkt []byte, content OmciContent, key OnuKey, instance uint16) ([]byte, error) {
 	AttributesMask := getAttributeMask(content)
 
 	for index := uint(16); index >= 1; index-- {
@@ -47,7 +48,13 @@ func GetSoftwareImageAttributes(pos *uint, pkt []byte, content OmciContent) ([]b
 		reqAttribute := Attribute & AttributesMask
 
 		if reqAttribute != 0 {
-			pkt, _ = SoftwareImageAttributeHandlers[SoftwareImageAttributes(reqAttribute)](pos, pkt)
+			handler, ok := SoftwareImageAttributeHandlers[SoftwareImageAttributes(reqAttribute)]
+			if !ok {
+				// Attributes without a handler are left out of the response
+				AttributesMask &^= reqAttribute
+				continue
+			}
+			pkt, _ = handler(pos, pkt, key, instance)
 		}
 	}
 
@@ -59,38 +66,39 @@ func GetSoftwareImageAttributes(pos *uint, pkt []byte, content OmciContent) ([]b
 
 }
 
-func GetSoftwareVersion(pos *uint, pkt []byte) ([]byte, error) {
+func GetSoftwareVersion(pos *uint, pkt []byte, key OnuKey, instance uint16) ([]byte, error) {
 	// 14 bytes
-	version := []byte("00000000000001")
-	for _, ch := range version {
-		pkt[*pos] = ch
-		*pos++
+	// The least significant byte of the instance is the slot of the image
+	version := ""
+	if slot := instance & 0xFF; int(slot) < len(OnuConfig{}.SoftwareVersions) {
+		version = getOnuConfig(key).SoftwareVersions[slot]
 	}
+	putConfigString(pos, pkt, version, softwareVersionLength, []byte("00000000000001"))
 	return pkt, nil
 }
 
-func GetIsCommited(pos *uint, pkt []byte) ([]byte, error) {
+func GetIsCommited(pos *uint, pkt []byte, _ OnuKey, _ uint16) ([]byte, error) {
 	// 1 bytes
 	pkt[*pos] = 0x01
 	*pos++
 	return pkt, nil
 }
 
-func GetIsActive(pos *uint, pkt []byte) ([]byte, error) {
+func GetIsActive(pos *uint, pkt []byte, _ OnuKey, _ uint16) ([]byte, error) {
 	// 1 bytes
 	pkt[*pos] = 0x01
 	*pos++
 	return pkt, nil
 }
 
-func GetIsValid(pos *uint, pkt []byte) ([]byte, error) {
+func GetIsValid(pos *uint, pkt []byte, _ OnuKey, _ uint16) ([]byte, error) {
 	// 1 byte
 	pkt[*pos] = 0x01
 	*pos++
 	return pkt, nil
 }
 
-func GetProductCode(pos *uint, pkt []byte) ([]byte, error) {
+func GetProductCode(pos *uint, pkt []byte, _ OnuKey, _ uint16) ([]byte, error) {
 	// 25 bytes
 	// BRCM has 25 nulls
 	for i := 1; i <= 25; i++ {
@@ -100,7 +108,7 @@ func GetProductCode(pos *uint, pkt []byte) ([]byte, error) {
 	return pkt, nil
 }
 
-func GetImageHash(pos *uint, pkt []byte) ([]byte, error) {
+func GetImageHash(pos *uint, pkt []byte, _ OnuKey, _ uint16) ([]byte, error) {
 	// 16 bytes
 	// BRCM has 16 nulls
 	for i := 1; i <= 16; i++ {
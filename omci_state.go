@@ -18,13 +18,21 @@ package core
 import (
 	"errors"
 	"fmt"
+	log "github.com/sirupsen/logrus"
 	"sync"
 )
 
 type OnuOmciState struct {
+	key               OnuKey
+	config            OnuConfig // Identity reported by the ONU, see SetOnuConfig
 	gemPortId         uint16
 	mibUploadCtr      uint16
+	mibUploadActive   bool // Set by a MibUpload, MibUploadNext is only accepted while true
+	mibResetRequired  bool // Set when the OLT and ONU MIBs got out of sync, cleared by a MibReset
+	onuDataPolls      int  // Number of Gets of the ONU Data
 	extraMibUploadCtr uint16 // this is only for debug purposes, will be removed in the future
+	corruptEntries    map[uint16]bool // MibUploadNext command numbers answered with a truncated frame
+	uploadedMes       []mibUploadMe // MEs reported after the static ones by the MIB upload in progress
 	uniGInstance      uint8
 	tcontInstance     uint8
 	pptpInstance      uint8
@@ -32,6 +40,20 @@ type OnuOmciState struct {
 	priorQPriority	  uint8 // Priority of the PriorityQueueG (0-7)
 	tcontPointer      uint8 // Tcont Pointer for PriorQ
 	state             istate
+	batteryBackup     bool
+	batteryLevel      uint8 // Remaining battery charge in percent
+	aniLos            bool  // Loss of signal on the ANI-G, see SimulateAniLos
+	telemetry         map[uint16]CircuitPackTelemetry // Telemetry set for the circuit packs, see SetTemperature
+	dbaGrants         map[uint16]bool // T-CONTs granted upstream bandwidth by the OLT, see SimulateDbaGrant
+	logicalOnuId      []byte // ONU-G logical ONU id set by the OLT, nil until then
+	logicalPassword   []byte // ONU-G logical password set by the OLT, nil until then
+	alarms            map[OmciMessageIdentifier]alarmBitmap
+	alarmSeqNumber    uint8
+	provisioningLock  bool // Rejects Create, Set and Delete while true
+	mib               map[OmciClass]map[uint16]MeAttributes // Instances of the MEs in MeDefinitions
+	pmThresholds      map[OmciMessageIdentifier]map[int]uint64 // Threshold values of the PM ME instances
+	pmCurrent         map[OmciMessageIdentifier]MeAttributes // Counters of the current interval of the PM ME instances
+	tableSnapshots    map[OmciMessageIdentifier]map[int][]byte // Tables read by the last Get, for GetNext
 }
 
 type istate int
@@ -47,19 +69,52 @@ var OnuOmciStateMap = map[OnuKey]*OnuOmciState{}
 var OnuOmciStateMapLock = sync.RWMutex{}
 
 func NewOnuOmciState() *OnuOmciState {
-	return &OnuOmciState{gemPortId: 0, mibUploadCtr: 0, uniGInstance: 1, tcontInstance: 0, pptpInstance: 1}
+	return newOnuOmciState(OnuKey{})
+}
+
+// newOnuOmciState returns the state of an ONU, key gives the ONU specific values such as MAC addresses
+func newOnuOmciState(key OnuKey) *OnuOmciState {
+	s := &OnuOmciState{key: key, config: onuConfigs[key], gemPortId: 0, mibUploadCtr: 0, uniGInstance: 1, tcontInstance: 0, pptpInstance: 1,
+		batteryLevel: 100, alarms: map[OmciMessageIdentifier]alarmBitmap{}, mib: map[OmciClass]map[uint16]MeAttributes{},
+		corruptEntries: map[uint16]bool{}, pmThresholds: map[OmciMessageIdentifier]map[int]uint64{}, pmCurrent: map[OmciMessageIdentifier]MeAttributes{},
+		tableSnapshots: map[OmciMessageIdentifier]map[int][]byte{}, telemetry: map[uint16]CircuitPackTelemetry{},
+		dbaGrants: map[uint16]bool{}}
+	s.createOnuMes()
+	return s
 }
 func (s *OnuOmciState) ResetOnuOmciState() {
 	// Resetting the counters  
+	s.resetMibUpload()
+	s.mibUploadActive = false
+	s.mibResetRequired = false
+	s.gemPortId = 0
+	s.alarmSeqNumber = 0
+	// The MEs go back to their defaults, without any alarm raised: there is no need to clear them
+	s.alarms = map[OmciMessageIdentifier]alarmBitmap{}
+	s.logicalOnuId = nil
+	s.logicalPassword = nil
+	s.mib = map[OmciClass]map[uint16]MeAttributes{}
+	s.pmThresholds = map[OmciMessageIdentifier]map[int]uint64{}
+	s.pmCurrent = map[OmciMessageIdentifier]MeAttributes{}
+	s.tableSnapshots = map[OmciMessageIdentifier]map[int][]byte{}
+	// The T-CONTs lose their Alloc-ID
+	s.dbaGrants = map[uint16]bool{}
+	s.createOnuMes()
+}
+
+// resetMibUpload moves the MIB upload cursor back to the first ME
+func (s *OnuOmciState) resetMibUpload() {
 	s.mibUploadCtr = 0
 	s.extraMibUploadCtr = 0
-	s.gemPortId = 0
+	s.uploadedMes = nil
 	s.uniGInstance = 1
 	s.tcontInstance = 0
 	s.pptpInstance = 1
+	s.priorQInstance = 0
 	s.tcontPointer = 0
 	s.priorQPriority = 0
 }
+
 func GetOnuOmciState(oltId int, intfId uint32, onuId uint32) istate {
 	key := OnuKey{oltId,intfId, onuId}
 	OnuOmciStateMapLock.RLock()
@@ -71,6 +126,53 @@ func GetOnuOmciState(oltId int, intfId uint32, onuId uint32) istate {
 	}
 }
 
+// IsProvisioningComplete reports whether a GEM port has been provisioned on an ONU
+func IsProvisioningComplete(oltId int, intfId uint32, onuId uint32) bool {
+	return GetOnuOmciState(oltId, intfId, onuId) == DONE
+}
+
+// SimulateReboot power cycles an ONU: as on a MibReset the MEs provisioned by the OLT and the counters
+// are lost, along with the alarms, and the ONU has to be provisioned again. The identity and the
+// software images of the ONU, see SetOnuConfig, are kept. The ONU-G reports the ONU disabled, then
+// enabled again, with attribute value changes of its operational state.
+func SimulateReboot(oltId int, intfId uint32, onuId uint32) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer unlockAndNotify()
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+		return errors.New(errmsg)
+	}
+
+	log.WithFields(log.Fields{
+		"IntfId": intfId,
+		"OnuId": onuId,
+	}).Info("Simulating ONU reboot")
+	queueNotification(attributeValueChange(key, ONUG, 0, onuGOperationalState, []byte{0x01}))
+	state.ResetOnuOmciState()
+	state.state = INCOMPLETE
+	queueNotification(attributeValueChange(key, ONUG, 0, onuGOperationalState, []byte{0x00}))
+	return nil
+}
+
+// NeedsMibReset reports whether the MIB of an ONU got out of sync with the one of the OLT,
+// e.g. after a MibUploadNext out of sequence, until the next MibReset
+func NeedsMibReset(oltId int, intfId uint32, onuId uint32) bool {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.RLock()
+	defer OnuOmciStateMapLock.RUnlock()
+	if onu, ok := OnuOmciStateMap[key]; ok {
+		return onu.mibResetRequired
+	}
+	return false
+}
+
+// setMibResetRequired is called with OnuOmciStateMapLock held
+func (s *OnuOmciState) setMibResetRequired() {
+	s.mibResetRequired = true
+}
+
 func GetGemPortId(oltId int, intfId uint32, onuId uint32) (uint16, error) {
 	key := OnuKey{oltId, intfId, onuId}
 	OnuOmciStateMapLock.RLock()
@@ -85,3 +187,26 @@ func GetGemPortId(oltId int, intfId uint32, onuId uint32) (uint16, error) {
 	errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
 	return 0, errors.New(errmsg)
 }
+
+// SetProvisioningLock locks or unlocks the provisioning of an ONU, while locked any
+// Create, Set or Delete is answered with device busy
+func SetProvisioningLock(oltId int, intfId uint32, onuId uint32, locked bool) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	if OnuOmciState, ok := OnuOmciStateMap[key]; ok {
+		OnuOmciState.provisioningLock = locked
+		return nil
+	}
+	errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", intfId, onuId)
+	return errors.New(errmsg)
+}
+
+func isProvisioningLocked(key OnuKey) bool {
+	OnuOmciStateMapLock.RLock()
+	defer OnuOmciStateMapLock.RUnlock()
+	if OnuOmciState, ok := OnuOmciStateMap[key]; ok {
+		return OnuOmciState.provisioningLock
+	}
+	return false
+}
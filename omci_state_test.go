@@ -0,0 +1,150 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestProvisioningLock(t *testing.T) {
+	onu := newTestOnu(t)
+	if err := SetProvisioningLock(0, onu.intfId, onu.onuId, true); err != nil {
+		t.Fatal(err)
+	}
+
+	gemPort := map[int][]byte{
+		GemPortCtpPortId:       {0x04, 0x01},
+		GemPortCtpTcontPointer: {0x80, 0x01},
+		GemPortCtpDirection:    {GemPortBidirectional},
+	}
+	if result := onu.create(GEMPortNetworkCTP, 0x0401, gemPort); result != DeviceBusy {
+		t.Errorf("Create while locked got result %d, expected %d", result, DeviceBusy)
+	}
+	if result, _ := onu.get(ONUG, 0, uint16(VendorID)); result != Success {
+		t.Errorf("Get while locked got result %d, expected %d", result, Success)
+	}
+
+	if err := SetProvisioningLock(0, onu.intfId, onu.onuId, false); err != nil {
+		t.Fatal(err)
+	}
+	if result := onu.create(GEMPortNetworkCTP, 0x0401, gemPort); result != Success {
+		t.Errorf("Create once unlocked got result %d, expected %d", result, Success)
+	}
+}
+
+func TestProvisioningLockUniAdminState(t *testing.T) {
+	onu := newTestOnu(t)
+	if err := SetProvisioningLock(0, onu.intfId, onu.onuId, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// A rejected Set of the administrative state leaves the UNI as it was
+	if result, _ := onu.set(PPTPEthernetUNI, 257, 0x0800, 0x01); result != DeviceBusy {
+		t.Errorf("Set while locked got result %d, expected %d", result, DeviceBusy)
+	}
+	onu.expectNoNotification()
+
+	if err := SetProvisioningLock(0, onu.intfId, onu.onuId, false); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := onu.set(PPTPEthernetUNI, 257, 0x0800, 0x01); result != Success {
+		t.Errorf("Set once unlocked got result %d, expected %d", result, Success)
+	}
+	if msg := onu.notification(); msg.Type != UniLinkDown {
+		t.Errorf("Got %s, expected %s", msg.Type, UniLinkDown)
+	}
+}
+
+func TestSimulateReboot(t *testing.T) {
+	onu := newTestOnu(t)
+	config := OnuConfig{SoftwareVersions: [2]string{"1.0.0", "1.1.0"}}
+	if err := SetOnuConfig(0, onu.intfId, onu.onuId, config); err != nil {
+		t.Fatal(err)
+	}
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+	drainChannel()
+
+	if err := SimulateReboot(0, onu.intfId, onu.onuId); err != nil {
+		t.Fatal(err)
+	}
+	// The ONU goes down, then up again
+	for _, operationalState := range []byte{0x01, 0x00} {
+		msg := onu.notification()
+		if msg.Type != AttributeValueChanged || binary.BigEndian.Uint16(msg.Packet[8:10]) != uint16(OperationalState) {
+			t.Fatalf("Got %s %x, expected an AVC of the ONU-G operational state", msg.Type, msg.Packet)
+		}
+		if msg.Packet[10] != operationalState {
+			t.Errorf("ONU-G operational state is %d, expected %d", msg.Packet[10], operationalState)
+		}
+	}
+	if result, _ := onu.get(GEMPortNetworkCTP, 0x0401, 0x8000); result != UnknownInstance {
+		t.Errorf("Get of the GEM port after a reboot got result %d, expected %d", result, UnknownInstance)
+	}
+	for slot, expected := range config.SoftwareVersions {
+		version := onu.mustGet(SoftwareImage, uint16(slot), 0x8000)[:len(expected)]
+		if string(version) != expected {
+			t.Errorf("Software version of slot %d is %q after a reboot, expected %q", slot, version, expected)
+		}
+	}
+	if state := GetOnuOmciState(0, onu.intfId, onu.onuId); state != INCOMPLETE {
+		t.Errorf("ONU state is %v after a reboot, expected INCOMPLETE", state)
+	}
+}
+
+func TestPptpAdminStateAvc(t *testing.T) {
+	onu := newTestOnu(t)
+	mask := attributeMaskBit(pptpEthernetUniOperationalState)
+
+	for _, adminState := range []byte{0x01, 0x00} {
+		if result, _ := onu.set(PPTPEthernetUNI, 257, 0x0800, adminState); result != Success {
+			t.Fatalf("Set of the administrative state %d got result %d", adminState, result)
+		}
+		// The UNI link alarm comes first
+		onu.notification()
+
+		// The operational state follows the administrative state
+		msg := onu.notification()
+		if msg.Type != AttributeValueChanged {
+			t.Fatalf("Got %s, expected %s", msg.Type, AttributeValueChanged)
+		}
+		if avcMask := binary.BigEndian.Uint16(msg.Packet[8:10]); avcMask != mask || msg.Packet[10] != adminState {
+			t.Errorf("AVC of mask %#04x value %d, expected mask %#04x value %d", avcMask, msg.Packet[10], mask, adminState)
+		}
+	}
+}
+
+func TestPptpAdminStateAvcOfAnyUni(t *testing.T) {
+	onu := newTestOnu(t)
+	mask := attributeMaskBit(pptpEthernetUniOperationalState)
+
+	// The lan port 2 locked along with its Ethernet loopback configuration, without any UNI link alarm
+	if result, _ := onu.set(PPTPEthernetUNI, 258, 0x1800, 0x00, 0x01); result != Success {
+		t.Fatalf("Set of the administrative state got result %d", result)
+	}
+	msg := onu.notification()
+	if msg.Type != AttributeValueChanged {
+		t.Fatalf("Got %s, expected %s", msg.Type, AttributeValueChanged)
+	}
+	if instance := binary.BigEndian.Uint16(msg.Packet[6:8]); instance != 258 {
+		t.Errorf("AVC of instance %d, expected 258", instance)
+	}
+	if avcMask := binary.BigEndian.Uint16(msg.Packet[8:10]); avcMask != mask || msg.Packet[10] != 0x01 {
+		t.Errorf("AVC of mask %#04x value %d, expected mask %#04x value 1", avcMask, msg.Packet[10], mask)
+	}
+	onu.expectNoNotification()
+}
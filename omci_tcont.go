@@ -0,0 +1,114 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// T-CONT attribute numbers
+const (
+	TcontAllocId = iota + 1
+	TcontModeIndicator
+	TcontPolicy
+)
+
+// TcontUnboundAllocId is the Alloc-ID of a T-CONT not used by the OLT
+const TcontUnboundAllocId uint16 = 0xFFFF
+
+// T-CONT policies
+const (
+	TcontPolicyNull           = 0
+	TcontPolicyStrictPriority = 1
+	TcontPolicyWrr            = 2
+)
+
+// T-CONT DBA statuses, see GetTcontDbaStatus
+const (
+	TcontDbaIdle    = 0
+	TcontDbaGranted = 1
+)
+
+// T-CONTs are created by the ONU, their values match the ones reported in the MIB upload
+func init() {
+	MeDefinitions[TCONT] = &MeDefinition{
+		Name: "Tcont",
+		Attributes: []AttributeDefinition{
+			{Name: "AllocId", Size: 2, Access: AttrRead | AttrWrite,
+				Default: []byte{byte(TcontUnboundAllocId >> 8), byte(TcontUnboundAllocId & 0xFF)}},
+			// Deprecated, always 1
+			{Name: "ModeIndicator", Size: 1, Access: AttrRead, Default: []byte{0x01}},
+			// Strict priority
+			{Name: "Policy", Size: 1, Access: AttrRead | AttrWrite, Default: []byte{TcontPolicyStrictPriority},
+				Range: true, Max: TcontPolicyWrr},
+		},
+		Instances: func() []uint16 {
+			instances := make([]uint16, 0, NumTcont)
+			for i := 1; i <= NumTcont; i++ {
+				instances = append(instances, 0x8000|uint16(i))
+			}
+			return instances
+		},
+	}
+}
+
+// SimulateDbaGrant sets whether the OLT grants upstream bandwidth to a T-CONT of an ONU, see GetTcontDbaStatus
+func SimulateDbaGrant(oltId int, intfId uint32, onuId uint32, instance uint16, granted bool) error {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.Lock()
+	defer OnuOmciStateMapLock.Unlock()
+	state, attrs, err := getTcont(key, instance)
+	if err != nil {
+		return err
+	}
+	if granted && attrs.uint16(TcontAllocId) == TcontUnboundAllocId {
+		return fmt.Errorf("T-CONT %#04x has no Alloc-ID", instance)
+	}
+
+	state.dbaGrants[instance] = granted
+	return nil
+}
+
+// GetTcontDbaStatus returns the DBA status of a T-CONT of an ONU: TcontDbaGranted while the OLT grants
+// it upstream bandwidth, TcontDbaIdle otherwise and once the OLT unbinds its Alloc-ID
+func GetTcontDbaStatus(oltId int, intfId uint32, onuId uint32, instance uint16) (uint8, error) {
+	key := OnuKey{oltId, intfId, onuId}
+	OnuOmciStateMapLock.RLock()
+	defer OnuOmciStateMapLock.RUnlock()
+	state, attrs, err := getTcont(key, instance)
+	if err != nil {
+		return 0, err
+	}
+	if !state.dbaGrants[instance] || attrs.uint16(TcontAllocId) == TcontUnboundAllocId {
+		return TcontDbaIdle, nil
+	}
+	return TcontDbaGranted, nil
+}
+
+func getTcont(key OnuKey, instance uint16) (*OnuOmciState, MeAttributes, error) {
+	state, ok := OnuOmciStateMap[key]
+	if !ok {
+		errmsg := fmt.Sprintf("ONU {intfid:%d, onuid:%d} - Failed to find a key in OnuOmciStateMap", key.IntfId, key.OnuId)
+		return nil, nil, errors.New(errmsg)
+	}
+	attrs, ok := state.getMe(TCONT, instance)
+	if !ok {
+		return nil, nil, fmt.Errorf("T-CONT %#04x not found", instance)
+	}
+	return state, attrs, nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestTcontAllocId(t *testing.T) {
+	onu := newTestOnu(t)
+	mask := attributeMaskBit(TcontAllocId)
+
+	if allocId := binary.BigEndian.Uint16(onu.mustGet(TCONT, 0x8001, mask)); allocId != TcontUnboundAllocId {
+		t.Errorf("Alloc-ID of an unbound T-CONT is %#04x, expected %#04x", allocId, TcontUnboundAllocId)
+	}
+
+	if result, _ := onu.set(TCONT, 0x8001, mask, 0x04, 0x01); result != Success {
+		t.Fatalf("Set of the Alloc-ID got result %d", result)
+	}
+	if allocId := binary.BigEndian.Uint16(onu.mustGet(TCONT, 0x8001, mask)); allocId != 0x0401 {
+		t.Errorf("Alloc-ID is %#04x, expected 0x0401", allocId)
+	}
+	// The other T-CONTs are still unbound
+	if allocId := binary.BigEndian.Uint16(onu.mustGet(TCONT, 0x8002, mask)); allocId != TcontUnboundAllocId {
+		t.Errorf("Alloc-ID of the second T-CONT is %#04x, expected %#04x", allocId, TcontUnboundAllocId)
+	}
+}
+
+func TestTcontPolicyDbaStatus(t *testing.T) {
+	onu := newTestOnu(t)
+	mask := attributeMaskBit(TcontPolicy)
+	dbaStatus := func() uint8 {
+		status, err := GetTcontDbaStatus(0, onu.intfId, onu.onuId, 0x8001)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return status
+	}
+
+	if result, _ := onu.set(TCONT, 0x8001, mask, TcontPolicyWrr); result != Success {
+		t.Fatalf("Set of the WRR policy got result %d", result)
+	}
+	if policy := onu.mustGet(TCONT, 0x8001, mask)[0]; policy != TcontPolicyWrr {
+		t.Errorf("Policy is %d, expected %d", policy, TcontPolicyWrr)
+	}
+	if status := dbaStatus(); status != TcontDbaIdle {
+		t.Errorf("DBA status is %d, expected %d", status, TcontDbaIdle)
+	}
+
+	// An unbound T-CONT gets no grant
+	if err := SimulateDbaGrant(0, onu.intfId, onu.onuId, 0x8001, true); err == nil {
+		t.Error("SimulateDbaGrant of an unbound T-CONT succeeded")
+	}
+	if result, _ := onu.set(TCONT, 0x8001, attributeMaskBit(TcontAllocId), 0x04, 0x01); result != Success {
+		t.Fatalf("Set of the Alloc-ID got result %d", result)
+	}
+	if err := SimulateDbaGrant(0, onu.intfId, onu.onuId, 0x8001, true); err != nil {
+		t.Fatal(err)
+	}
+	if status := dbaStatus(); status != TcontDbaGranted {
+		t.Errorf("DBA status is %d, expected %d", status, TcontDbaGranted)
+	}
+	// The G.988 attributes are the only ones
+	if result, _ := onu.get(TCONT, 0x8001, attributeMaskBit(TcontPolicy+1)); result == Success {
+		t.Error("Get of a T-CONT attribute 4 succeeded")
+	}
+
+	result, failed := onu.set(TCONT, 0x8001, mask, TcontPolicyWrr+1)
+	if result != AttributeFailure || failed != mask {
+		t.Errorf("Set of an unknown policy got result %d failed mask %#04x, expected %d and %#04x",
+			result, failed, AttributeFailure, mask)
+	}
+
+	// Unbinding the Alloc-ID ends the grant
+	if result, _ := onu.set(TCONT, 0x8001, attributeMaskBit(TcontAllocId), 0xFF, 0xFF); result != Success {
+		t.Fatalf("Set of the unbound Alloc-ID got result %d", result)
+	}
+	if status := dbaStatus(); status != TcontDbaIdle {
+		t.Errorf("DBA status of an unbound T-CONT is %d, expected %d", status, TcontDbaIdle)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// TCP/UDP Config Data attribute numbers
+const (
+	TcpUdpPortId = iota + 1
+	TcpUdpProtocol
+	TcpUdpTosDiffservField
+	TcpUdpIpHostPointer
+)
+
+// The TCP/UDP Config Data are created by the OLT, on top of an IP host
+func init() {
+	MeDefinitions[TCPUDPConfigData] = &MeDefinition{
+		Name: "TcpUdpConfigData",
+		Attributes: []AttributeDefinition{
+			{Name: "PortId", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "Protocol", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "TosDiffservField", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "IpHostPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+		Validate: validateTcpUdpConfigData,
+	}
+}
+
+func validateTcpUdpConfigData(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	var failed uint16
+
+	if _, ok := state.getMe(IPHostConfigData, attrs.uint16(TcpUdpIpHostPointer)); !ok {
+		failed |= attributeMaskBit(TcpUdpIpHostPointer)
+	}
+
+	return failed
+}
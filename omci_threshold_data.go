@@ -0,0 +1,42 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+)
+
+// thresholdValuesPerMe is the number of threshold values of a Threshold Data 1 or 2 ME,
+// Threshold Data 2 holds the threshold values 8 to 14
+const thresholdValuesPerMe = 7
+
+func init() {
+	MeDefinitions[ThresholdData1] = newThresholdDataDefinition("ThresholdData1", 1)
+	MeDefinitions[ThresholdData2] = newThresholdDataDefinition("ThresholdData2", thresholdValuesPerMe+1)
+}
+
+func newThresholdDataDefinition(name string, first int) *MeDefinition {
+	attributes := make([]AttributeDefinition, 0, thresholdValuesPerMe)
+	for i := 0; i < thresholdValuesPerMe; i++ {
+		attributes = append(attributes, AttributeDefinition{
+			Name:   fmt.Sprintf("ThresholdValue%d", first+i),
+			Size:   4,
+			Access: AttrRead | AttrWrite | AttrSetByCreate,
+		})
+	}
+	return &MeDefinition{Name: name, Attributes: attributes}
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestThresholdDataTca(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(ThresholdData1, 0x0001, map[int][]byte{
+		1: {0x00, 0x00, 0x00, 0x03},
+		3: {0x00, 0x00, 0x00, 0x64},
+	})
+	values := onu.mustGet(ThresholdData1, 0x0001, 0xa000)
+	if fcs, late := binary.BigEndian.Uint32(values[0:4]), binary.BigEndian.Uint32(values[4:8]); fcs != 3 || late != 100 {
+		t.Errorf("Threshold values are %d and %d, expected 3 and 100", fcs, late)
+	}
+
+	onu.mustCreate(EthernetPMHistoryData, 0x0101, map[int][]byte{EthernetPmThresholdDataId: {0x00, 0x01}})
+	if err := IncrementPmCounter(0, onu.intfId, onu.onuId, EthernetPMHistoryData, 0x0101, EthernetPmFcsErrors, 3); err != nil {
+		t.Fatal(err)
+	}
+	msg := onu.notification()
+	if msg.Type != AlarmRaised || !alarmRaised(msg.Packet, 0) {
+		t.Fatalf("Got %s %x, expected the FCS errors TCA", msg.Type, msg.Packet)
+	}
+
+	// A Set of the Threshold Data 1 changes the thresholds of the PM MEs pointing to it
+	if result, _ := onu.set(ThresholdData1, 0x0001, 0x2000, 0x00, 0x00, 0x00, 0x02); result != Success {
+		t.Fatalf("Set got result %d", result)
+	}
+	if err := IncrementPmCounter(0, onu.intfId, onu.onuId, EthernetPMHistoryData, 0x0101, EthernetPmLateCollisionCounter, 2); err != nil {
+		t.Fatal(err)
+	}
+	msg = onu.notification()
+	if msg.Type != AlarmRaised || !alarmRaised(msg.Packet, 2) {
+		t.Fatalf("Got %s %x, expected the late collision TCA", msg.Type, msg.Packet)
+	}
+}
+
+func TestDeletedPmMeTca(t *testing.T) {
+	onu := newTestOnu(t)
+	key := OnuKey{0, onu.intfId, onu.onuId}
+	onu.mustCreate(ThresholdData1, 0x0001, map[int][]byte{1: {0x00, 0x00, 0x00, 0x03}})
+	pmAttrs := map[int][]byte{EthernetPmThresholdDataId: {0x00, 0x01}}
+	onu.mustCreate(EthernetPMHistoryData, 0x0101, pmAttrs)
+	if err := IncrementPmCounter(0, onu.intfId, onu.onuId, EthernetPMHistoryData, 0x0101, EthernetPmFcsErrors, 3); err != nil {
+		t.Fatal(err)
+	}
+	if msg := onu.notification(); msg.Type != AlarmRaised || !alarmRaised(msg.Packet, 0) {
+		t.Fatalf("Got %s %x, expected the FCS errors TCA", msg.Type, msg.Packet)
+	}
+
+	if result := onu.result(onu.send(Delete, EthernetPMHistoryData, 0x0101, nil)); result != Success {
+		t.Fatalf("Delete got result %d", result)
+	}
+	if err := IncrementPmCounter(0, onu.intfId, onu.onuId, EthernetPMHistoryData, 0x0101, EthernetPmFcsErrors, 3); err == nil {
+		t.Error("Counting on a deleted PM ME succeeded")
+	}
+
+	// The TCA scheduled for the deleted instance is dropped, the battery-low alarm that follows is sent
+	LoadNotificationScript([]ScheduledNotification{
+		{Key: key, Type: AlarmRaised, Class: EthernetPMHistoryData, Instance: 0x0101, Alarm: 1},
+		{Delay: 10 * time.Millisecond, Key: key, Type: AlarmRaised, Class: ONUG, Alarm: OnuGBatteryLowAlarm},
+	})
+	defer LoadNotificationScript(nil)
+	if err := StartNotificationScript(); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown()
+	if msg := onu.notification(); msg.Type != AlarmRaised || OmciClass(binary.BigEndian.Uint16(msg.Packet[4:6])) != ONUG {
+		t.Fatalf("Got %s %x, expected the battery-low alarm", msg.Type, msg.Packet)
+	}
+
+	// A new instance with the same id doesn't inherit the TCA of the deleted one
+	onu.mustCreate(EthernetPMHistoryData, 0x0101, pmAttrs)
+	if err := IncrementPmCounter(0, onu.intfId, onu.onuId, EthernetPMHistoryData, 0x0101, EthernetPmFcsErrors, 3); err != nil {
+		t.Fatal(err)
+	}
+	if msg := onu.notification(); msg.Type != AlarmRaised || !alarmRaised(msg.Packet, 0) {
+		t.Fatalf("Got %s %x, expected the FCS errors TCA of the new instance", msg.Type, msg.Packet)
+	}
+}
+
+func TestThresholdData64BitIdTca(t *testing.T) {
+	onu := newTestOnu(t)
+	// The Enhanced FEC PM points to a Threshold Data 64-bit, not to this Threshold Data 1
+	onu.mustCreate(ThresholdData1, 0x0001, map[int][]byte{1: {0x00, 0x00, 0x00, 0x03}})
+	onu.mustCreate(EnhancedFecPMHistoryData, 0x0101, map[int][]byte{EnhancedFecPmThresholdDataId: {0x00, 0x01}})
+	increment := func(delta uint64) {
+		err := IncrementPmCounter(0, onu.intfId, onu.onuId, EnhancedFecPMHistoryData, 0x0101, EnhancedFecPmCorrectedBytes, delta)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	increment(3)
+	onu.expectNoNotification()
+
+	if err := SetPmThreshold(0, onu.intfId, onu.onuId, EnhancedFecPMHistoryData, 0x0101, 1, 5); err != nil {
+		t.Fatal(err)
+	}
+	increment(2)
+	if msg := onu.notification(); msg.Type != AlarmRaised || !alarmRaised(msg.Packet, 0) {
+		t.Fatalf("Got %s %x, expected the corrected bytes TCA", msg.Type, msg.Packet)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+)
+
+// Traffic Descriptor attribute numbers
+const (
+	TrafficDescriptorCir = iota + 1
+	TrafficDescriptorPir
+	TrafficDescriptorCbs
+	TrafficDescriptorPbs
+	TrafficDescriptorColourMode
+	TrafficDescriptorIngressColourMarking
+	TrafficDescriptorEgressColourMarking
+	TrafficDescriptorMeterType
+)
+
+func init() {
+	MeDefinitions[TrafficDescriptor] = &MeDefinition{
+		Name: "TrafficDescriptor",
+		Attributes: []AttributeDefinition{
+			{Name: "Cir", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "Pir", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "Cbs", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "Pbs", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "ColourMode", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "IngressColourMarking", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "EgressColourMarking", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "MeterType", Size: 1, Access: AttrRead | AttrSetByCreate},
+		},
+		Validate: validateTrafficDescriptor,
+	}
+}
+
+func validateTrafficDescriptor(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	var failed uint16
+
+	// A zero PIR means the PIR is the CIR
+	cir := binary.BigEndian.Uint32(attrs[TrafficDescriptorCir])
+	pir := binary.BigEndian.Uint32(attrs[TrafficDescriptorPir])
+	if pir != 0 && pir < cir {
+		failed |= attributeMaskBit(TrafficDescriptorPir)
+	}
+	// Colour blind or colour aware
+	if attrs[TrafficDescriptorColourMode][0] > 1 {
+		failed |= attributeMaskBit(TrafficDescriptorColourMode)
+	}
+	// Not specified, RFC 4115 or RFC 2698
+	if attrs[TrafficDescriptorMeterType][0] > 2 {
+		failed |= attributeMaskBit(TrafficDescriptorMeterType)
+	}
+
+	return failed
+}
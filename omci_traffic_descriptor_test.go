@@ -0,0 +1,54 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestTrafficDescriptor(t *testing.T) {
+	onu := newTestOnu(t)
+	onu.mustCreate(TrafficDescriptor, 0x0001, map[int][]byte{
+		TrafficDescriptorCir: {0x00, 0x01, 0x86, 0xa0},
+		TrafficDescriptorPir: {0x00, 0x03, 0x0d, 0x40},
+	})
+	values := onu.mustGet(TrafficDescriptor, 0x0001, attributeMaskBit(TrafficDescriptorCir)|attributeMaskBit(TrafficDescriptorPir))
+	if cir, pir := binary.BigEndian.Uint32(values[0:4]), binary.BigEndian.Uint32(values[4:8]); cir != 100000 || pir != 200000 {
+		t.Errorf("CIR %d and PIR %d, expected 100000 and 200000", cir, pir)
+	}
+
+	// The GEM ports may only point to existing traffic descriptors
+	attrs := gemPortAttributes(0x0401)
+	attrs[GemPortCtpTrafficDescriptorPointerUpstream] = []byte{0x00, 0x02}
+	if result := onu.create(GEMPortNetworkCTP, 0x0401, attrs); result != ParameterError {
+		t.Errorf("Create of a GEM port with a missing traffic descriptor got result %d, expected %d", result, ParameterError)
+	}
+	attrs[GemPortCtpTrafficDescriptorPointerUpstream] = []byte{0x00, 0x01}
+	onu.mustCreate(GEMPortNetworkCTP, 0x0401, attrs)
+}
+
+func TestTrafficDescriptorPirBelowCir(t *testing.T) {
+	onu := newTestOnu(t)
+	result := onu.create(TrafficDescriptor, 0x0001, map[int][]byte{
+		TrafficDescriptorCir: {0x00, 0x03, 0x0d, 0x40},
+		TrafficDescriptorPir: {0x00, 0x01, 0x86, 0xa0},
+	})
+	if result != ParameterError {
+		t.Errorf("Create got result %d, expected %d", result, ParameterError)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ValidateFrame runs the structural checks of an OMCI request done by OmciSim in StrictMode: frame
+// length, device identifier, transaction id, message type, ME class and contents length. It neither processes the
+// request nor touches the simulator state.
+func ValidateFrame(pkt []byte) error {
+	// The frame length follows from the device identifier, and for extended frames the contents length
+	frames, err := SplitFrames(pkt)
+	if err != nil {
+		return err
+	}
+	if len(frames) != 1 {
+		return fmt.Errorf("Expected a single OMCI frame, got %d", len(frames))
+	}
+
+	if binary.BigEndian.Uint16(pkt[0:2]) == AutonomousTransactionId && Config.DropZeroTransactionId {
+		return errors.New("Transaction id 0 is reserved for autonomous messages")
+	}
+
+	msgType := OmciMsgType(pkt[2] & 0x1F)
+	if _, ok := Handlers[msgType]; !ok {
+		return fmt.Errorf("Unsupported message type %d", msgType)
+	}
+
+	class := OmciClass(binary.BigEndian.Uint16(pkt[4:6]))
+	instance := binary.BigEndian.Uint16(pkt[6:8])
+	def, modeled := MeDefinitions[class]
+	if !modeled && legacyGetter(class, instance, OnuKey{}) == nil && !isClassSupported(class) {
+		return fmt.Errorf("Unsupported ME class %d", class)
+	}
+
+	var contents []byte
+	if pkt[3] == ExtendedDeviceId {
+		contentsLength := int(binary.BigEndian.Uint16(pkt[8:10]))
+		if contentsLength > ExtendedMaxContentsLength {
+			return fmt.Errorf("Extended contents length %d exceeds %d bytes", contentsLength, ExtendedMaxContentsLength)
+		}
+		contents = pkt[ExtendedHeaderLength : ExtendedHeaderLength+contentsLength]
+	} else {
+		contents = pkt[8:40]
+	}
+	if !modeled {
+		return nil
+	}
+
+	// The contents of the Creates and Sets of the modeled MEs must carry the attributes they write
+	var length int
+	switch msgType {
+	case Create:
+		for _, attrDef := range def.Attributes {
+			if attrDef.Access&AttrSetByCreate != 0 {
+				length += attrDef.Size
+			}
+		}
+	case Set:
+		if len(contents) < 2 {
+			return errors.New("Set contents too short for an attribute mask")
+		}
+		mask := binary.BigEndian.Uint16(contents[0:2])
+		length = 2
+		for index := 1; index <= 16; index++ {
+			if mask&attributeMaskBit(index) == 0 {
+				continue
+			}
+			if index > len(def.Attributes) {
+				return fmt.Errorf("Set of attribute %d not defined by %s", index, def.Name)
+			}
+			length += def.Attributes[index-1].Size
+		}
+	}
+	if length > len(contents) {
+		return fmt.Errorf("%s of %s needs %d bytes of contents, %d available", msgType.PrettyPrint(), def.Name,
+			length, len(contents))
+	}
+	return nil
+}
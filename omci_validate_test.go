@@ -0,0 +1,66 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFrame(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.StrictMode = true
+
+	if err := ValidateFrame(EncodeRequest(1, Get, ONUG, 0, []byte{0x80, 0x00})); err != nil {
+		t.Errorf("Validation of a Get of the ONU-G failed: %s", err)
+	}
+	gemPort := EncodeCreate(1, GEMPortNetworkCTP, 0x0401, gemPortAttributes(0x0401))
+	if err := ValidateFrame(gemPort); err != nil {
+		t.Errorf("Validation of a Create of a GEM port failed: %s", err)
+	}
+
+	badDeviceId := EncodeRequest(1, Get, ONUG, 0, []byte{0x80, 0x00})
+	badDeviceId[3] = 0x0c
+	unknownMsgType := EncodeRequest(1, Get, ONUG, 0, []byte{0x80, 0x00})
+	unknownMsgType[2] = 0x40 | 0x1f
+	// The Extended VLAN TPIDs, downstream mode, associated ME pointer and DSCP mapping need 31 bytes
+	setOverrun := EncodeRequest(1, Set, ExtendedVlanTagging, 0x0501, []byte{0x3b, 0x00})
+	extendedOverrun := EncodeExtendedRequest(1, Get, ONUG, 0, []byte{0x80, 0x00})
+	extendedOverrun[8], extendedOverrun[9] = 0x07, 0xd0
+
+	tests := []struct {
+		name  string
+		frame []byte
+		err   string
+	}{
+		{"short frame", gemPort[:20], "Truncated OMCI frame"},
+		{"two frames", append(append([]byte{}, gemPort...), gemPort...), "single OMCI frame"},
+		{"bad device id", badDeviceId, "Invalid device identifier"},
+		{"unknown message type", unknownMsgType, "Unsupported message type"},
+		{"unknown class", EncodeRequest(1, Get, unmodeledClass, 0, []byte{0x80, 0x00}), "Unsupported ME class"},
+		{"Set contents overrun", setOverrun, "needs 33 bytes of contents"},
+		{"extended contents overrun", extendedOverrun, "Truncated OMCI frame"},
+	}
+	for _, test := range tests {
+		err := ValidateFrame(test.frame)
+		if err == nil {
+			t.Errorf("Validation of a %s succeeded", test.name)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Validation of a %s failed with %q, expected %q", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// Virtual Ethernet Interface Point attribute numbers
+const (
+	VeipAdministrativeState = iota + 1
+	VeipOperationalState
+	VeipInterdomainName
+	VeipTcpUdpPointer
+	VeipIanaAssignedPort
+)
+
+func init() {
+	MeDefinitions[VirtualEthernetInterfacePoint] = &MeDefinition{
+		Name: "VirtualEthernetInterfacePoint",
+		Attributes: []AttributeDefinition{
+			{Name: "AdministrativeState", Size: 1, Access: AttrRead | AttrWrite, Range: true, Max: 1},
+			{Name: "OperationalState", Size: 1, Access: AttrRead},
+			{Name: "InterdomainName", Size: 25, Access: AttrRead | AttrWrite},
+			{Name: "TcpUdpPointer", Size: 2, Access: AttrRead | AttrWrite, Default: []byte{0xFF, 0xFF}},
+			{Name: "IanaAssignedPort", Size: 2, Access: AttrRead, Default: []byte{0xFF, 0xFF}},
+		},
+		Validate:  validateVeip,
+		Instances: veipInstances,
+		MibUpload: true,
+	}
+}
+
+func veipInstances() []uint16 {
+	instances := make([]uint16, 0, Config.NumVeip)
+	for i := 1; i <= Config.NumVeip; i++ {
+		instances = append(instances, 0x0500|uint16(i))
+	}
+	return instances
+}
+
+// validateVeip checks that the TCP/UDP pointer, unless null, points to a TCP/UDP Config Data,
+// which in turn points to an IP host
+func validateVeip(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	var failed uint16
+
+	tcpUdp := attrs.uint16(VeipTcpUdpPointer)
+	if _, ok := state.getMe(TCPUDPConfigData, tcpUdp); !isNullPointer(tcpUdp) && !ok {
+		failed |= attributeMaskBit(VeipTcpUdpPointer)
+	}
+
+	return failed
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestVeipInterdomainNameAndTcpUdpPointer(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.NumVeip = 1
+	Config.NumIpHost = 1
+	onu := newTestOnu(t)
+	veip := uint16(0x0501)
+	tcpUdp := attributeMaskBit(VeipTcpUdpPointer)
+
+	// The TCP/UDP Config Data has to exist
+	if result, failed := onu.set(VirtualEthernetInterfacePoint, veip, tcpUdp, 0x00, 0x01); result != ParameterError || failed != tcpUdp {
+		t.Errorf("Set of a dangling TCP/UDP pointer got result %d failed mask %#04x, expected %d and %#04x",
+			result, failed, ParameterError, tcpUdp)
+	}
+	onu.mustCreate(TCPUDPConfigData, 0x0001, map[int][]byte{
+		TcpUdpPortId:        {0x13, 0xc4},
+		TcpUdpProtocol:      {0x11},
+		TcpUdpIpHostPointer: {0x00, 0x01},
+	})
+
+	name := make([]byte, 25)
+	copy(name, "voip-domain")
+	mask := attributeMaskBit(VeipInterdomainName) | tcpUdp
+	if result, failed := onu.set(VirtualEthernetInterfacePoint, veip, mask, append(name, 0x00, 0x01)...); result != Success {
+		t.Fatalf("Set of the interdomain name and TCP/UDP pointer got result %d failed mask %#04x", result, failed)
+	}
+	// Both attributes take 27 bytes, more than a baseline Get response carries
+	request := EncodeExtendedRequest(onu.nextTxId(), Get, VirtualEthernetInterfacePoint, veip, []byte{byte(mask >> 8), byte(mask)})
+	result, values, err := DecodeExtendedGetResponse(VirtualEthernetInterfacePoint, onu.sendFrame(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != Success {
+		t.Fatalf("Extended Get got result %d, expected %d", result, Success)
+	}
+	if !bytes.Equal(values[VeipInterdomainName], name) {
+		t.Errorf("Interdomain name is %q, expected %q", values[VeipInterdomainName], name)
+	}
+	if pointer := binary.BigEndian.Uint16(values[VeipTcpUdpPointer]); pointer != 0x0001 {
+		t.Errorf("TCP/UDP pointer is %#04x, expected 0x0001", pointer)
+	}
+}
+
+func TestVeipMibUpload(t *testing.T) {
+	defer func(config OmciSimConfig) { Config = config }(Config)
+	Config.CheckMibUpload = true
+	Config.NumVeip = 1
+	onu := newTestOnu(t)
+
+	// The VEIP created by the ONU is reported after the MEs every ONU has
+	n := onu.startMibUpload()
+	if n <= numStaticMibUploads {
+		t.Fatalf("The MIB upload has %d entries, expected the VEIP after the %d static ones", n, numStaticMibUploads)
+	}
+	var uploaded uint16
+	for i := numStaticMibUploads; i < n; i++ {
+		resp, err := onu.mibUploadNext(i)
+		if err != nil {
+			t.Fatalf("MibUploadNext %d failed: %v", i, err)
+		}
+		if class, instance := OmciClass(binary.BigEndian.Uint16(resp[8:10])), binary.BigEndian.Uint16(resp[10:12]); class != VirtualEthernetInterfacePoint || instance != 0x0501 {
+			t.Fatalf("MibUploadNext %d reports %s %#04x, expected the VEIP", i, class.PrettyPrint(), instance)
+		}
+		uploaded |= binary.BigEndian.Uint16(resp[12:14])
+	}
+	if uploaded != 0xF800 {
+		t.Errorf("The MIB upload reports attributes %#04x, expected all of them", uploaded)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+// VoIP Voice CTP attribute numbers
+const (
+	VoipVoiceCtpUserProtocolPointer = iota + 1
+	VoipVoiceCtpPptpPointer
+	VoipVoiceCtpVoipMediaProfilePointer
+	VoipVoiceCtpSignallingCode
+)
+
+// SIP Agent Config Data attribute numbers
+const (
+	SipAgentProxyServerAddressPointer = iota + 1
+	SipAgentOutboundProxyAddressPointer
+	SipAgentPrimarySipDns
+	SipAgentSecondarySipDns
+	SipAgentTcpUdpPointer
+	SipAgentSipRegExpTime
+	SipAgentSipReregHeadStartTime
+	SipAgentHostPartUri
+	SipAgentSipStatus
+	SipAgentSipRegistrar
+	SipAgentSoftswitch
+	SipAgentSipResponseTable
+	SipAgentSipOptionTransmitControl
+	SipAgentSipUriFormat
+	SipAgentRedundantSipAgentPointer
+)
+
+// SIP User Data attribute numbers
+const (
+	SipUserSipAgentPointer = iota + 1
+	SipUserUserPartAor
+	SipUserSipDisplayName
+	SipUserUsernamePassword
+	SipUserVoicemailServerSipUri
+	SipUserVoicemailSubscriptionExpirationTime
+	SipUserNetworkDialPlanPointer
+	SipUserApplicationServicesProfilePointer
+	SipUserFeatureCodePointer
+	SipUserPptpPointer
+	SipUserReleaseTimer
+	SipUserRohTimer
+)
+
+func init() {
+	MeDefinitions[VoIPVoiceCTP] = &MeDefinition{
+		Name: "VoipVoiceCtp",
+		Attributes: []AttributeDefinition{
+			{Name: "UserProtocolPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PptpPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "VoipMediaProfilePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "SignallingCode", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+		Validate: validateVoipVoiceCtp,
+	}
+
+	// The SIP response table is not modeled
+	MeDefinitions[SIPAgentConfigData] = &MeDefinition{
+		Name: "SipAgentConfigData",
+		Attributes: []AttributeDefinition{
+			{Name: "ProxyServerAddressPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "OutboundProxyAddressPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PrimarySipDns", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "SecondarySipDns", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "TcpUdpPointer", Size: 2, Access: AttrRead | AttrWrite},
+			// 3600 seconds by default
+			{Name: "SipRegExpTime", Size: 4, Access: AttrRead | AttrWrite, Default: []byte{0x00, 0x00, 0x0e, 0x10}},
+			// 360 seconds by default
+			{Name: "SipReregHeadStartTime", Size: 4, Access: AttrRead | AttrWrite, Default: []byte{0x00, 0x00, 0x01, 0x68}},
+			{Name: "HostPartUri", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "SipStatus", Size: 1, Access: AttrRead},
+			{Name: "SipRegistrar", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "Softswitch", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "SipResponseTable"},
+			{Name: "SipOptionTransmitControl", Size: 1, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "SipUriFormat", Size: 1, Access: AttrRead},
+			{Name: "RedundantSipAgentPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+		},
+	}
+
+	MeDefinitions[SIPUserData] = &MeDefinition{
+		Name: "SipUserData",
+		Attributes: []AttributeDefinition{
+			{Name: "SipAgentPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "UserPartAor", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "SipDisplayName", Size: 25, Access: AttrRead | AttrWrite},
+			{Name: "UsernamePassword", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "VoicemailServerSipUri", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			// 3600 seconds by default
+			{Name: "VoicemailSubscriptionExpirationTime", Size: 4, Access: AttrRead | AttrWrite | AttrSetByCreate, Default: []byte{0x00, 0x00, 0x0e, 0x10}},
+			{Name: "NetworkDialPlanPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "ApplicationServicesProfilePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "FeatureCodePointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			{Name: "PptpPointer", Size: 2, Access: AttrRead | AttrWrite | AttrSetByCreate},
+			// 10 seconds by default
+			{Name: "ReleaseTimer", Size: 1, Access: AttrRead | AttrWrite, Default: []byte{0x0a}},
+			// 15 seconds by default
+			{Name: "RohTimer", Size: 1, Access: AttrRead | AttrWrite, Default: []byte{0x0f}},
+		},
+		Validate: validateSipUserData,
+	}
+}
+
+// validateVoipVoiceCtp checks that the VoIP Voice CTP links a SIP user to an existing POTS UNI
+func validateVoipVoiceCtp(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	var failed uint16
+
+	// With SIP signalling the user protocol pointer points to a SIP User Data
+	if _, ok := state.getMe(SIPUserData, attrs.uint16(VoipVoiceCtpUserProtocolPointer)); !ok {
+		failed |= attributeMaskBit(VoipVoiceCtpUserProtocolPointer)
+	}
+	if _, ok := state.getMe(PPTPPotsUNI, attrs.uint16(VoipVoiceCtpPptpPointer)); !ok {
+		failed |= attributeMaskBit(VoipVoiceCtpPptpPointer)
+	}
+	// 1 is SIP, the only signalling protocol simulated
+	if attrs[VoipVoiceCtpSignallingCode][0] != 1 {
+		failed |= attributeMaskBit(VoipVoiceCtpSignallingCode)
+	}
+
+	return failed
+}
+
+// validateSipUserData checks that the SIP User Data belongs to an existing SIP agent and POTS UNI
+func validateSipUserData(state *OnuOmciState, instance uint16, attrs MeAttributes) uint16 {
+	var failed uint16
+
+	if _, ok := state.getMe(SIPAgentConfigData, attrs.uint16(SipUserSipAgentPointer)); !ok {
+		failed |= attributeMaskBit(SipUserSipAgentPointer)
+	}
+	if _, ok := state.getMe(PPTPPotsUNI, attrs.uint16(SipUserPptpPointer)); !ok {
+		failed |= attributeMaskBit(SipUserPptpPointer)
+	}
+
+	return failed
+}
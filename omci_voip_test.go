@@ -0,0 +1,79 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestVoipVoiceCtp(t *testing.T) {
+	onu := newTestVoiceOnu(t)
+	onu.mustCreate(SIPAgentConfigData, 0x0001, nil)
+	onu.mustCreate(SIPUserData, 0x0001, map[int][]byte{
+		SipUserSipAgentPointer: {0x00, 0x01},
+		SipUserPptpPointer:     {0x01, 0x01},
+	})
+	onu.mustCreate(VoIPVoiceCTP, 0x0001, map[int][]byte{
+		VoipVoiceCtpUserProtocolPointer: {0x00, 0x01},
+		VoipVoiceCtpPptpPointer:         {0x01, 0x01},
+		VoipVoiceCtpSignallingCode:      {0x01},
+	})
+
+	mask := attributeMaskBit(VoipVoiceCtpUserProtocolPointer) | attributeMaskBit(VoipVoiceCtpPptpPointer)
+	values := onu.mustGet(VoIPVoiceCTP, 0x0001, mask)
+	if user, pptp := binary.BigEndian.Uint16(values[0:2]), binary.BigEndian.Uint16(values[2:4]); user != 0x0001 || pptp != 0x0101 {
+		t.Errorf("User protocol pointer %#04x and PPTP pointer %#04x, expected 0x0001 and 0x0101", user, pptp)
+	}
+}
+
+func TestVoipVoiceCtpUserProtocolPointer(t *testing.T) {
+	onu := newTestVoiceOnu(t)
+	onu.mustCreate(SIPAgentConfigData, 0x0001, nil)
+
+	// With SIP signalling the user protocol pointer points to a SIP User Data, not to the SIP agent
+	result := onu.create(VoIPVoiceCTP, 0x0001, map[int][]byte{
+		VoipVoiceCtpUserProtocolPointer: {0x00, 0x01},
+		VoipVoiceCtpPptpPointer:         {0x01, 0x01},
+		VoipVoiceCtpSignallingCode:      {0x01},
+	})
+	if result != ParameterError {
+		t.Errorf("Create got result %d, expected %d", result, ParameterError)
+	}
+}
+
+func TestSipUserDataPointers(t *testing.T) {
+	onu := newTestVoiceOnu(t)
+	result := onu.create(SIPUserData, 0x0001, map[int][]byte{
+		SipUserSipAgentPointer: {0x00, 0x01},
+		SipUserPptpPointer:     {0x01, 0x01},
+	})
+	if result != ParameterError {
+		t.Errorf("Create without a SIP agent got result %d, expected %d", result, ParameterError)
+	}
+}
+
+func TestSipAgentUriFormatReadOnly(t *testing.T) {
+	onu := newTestVoiceOnu(t)
+	onu.mustCreate(SIPAgentConfigData, 0x0001, nil)
+	if result, _ := onu.set(SIPAgentConfigData, 0x0001, attributeMaskBit(SipAgentSipUriFormat), 0x01); result == Success {
+		t.Error("Set of the SIP URI format succeeded")
+	}
+	if values := onu.mustGet(SIPAgentConfigData, 0x0001, attributeMaskBit(SipAgentSipUriFormat)); values[0] != 0x00 {
+		t.Errorf("SIP URI format is %d, expected 0", values[0])
+	}
+}